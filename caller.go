@@ -0,0 +1,58 @@
+package golog
+
+import (
+	"runtime"
+	"strings"
+)
+
+// Caller is the location of the code that sent a message.
+type Caller = Frame
+
+// captureCaller returns the caller skip frames above the function calling captureCaller.
+// Frames of the runtime package (e.g. panic handling when called from Logger.Recover) are skipped as well.
+func captureCaller(skip int) Caller {
+	var pcs [8]uintptr
+	n := runtime.Callers(skip+2, pcs[:])
+	return callerFromPCs(pcs[:n])
+}
+
+func callerFromPCs(pcs []uintptr) Caller {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !more || !strings.HasPrefix(frame.Function, "runtime.") {
+			return Caller{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			}
+		}
+	}
+}
+
+// CallerSkip skips additional frames when capturing the caller. Use it in helpers that send messages
+// on behalf of their callers, so the location of the helper caller is recorded.
+func (m Message) CallerSkip(skip int) Message {
+	if m.data != nil {
+		m.data.callerSkip += skip
+	}
+	return m
+}
+
+// SetIncludeCaller enables capturing callers of messages sent by this logger and loggers created from it.
+// Callers are captured for every logger when Config.IncludeCaller is enabled.
+func (l *Logger) SetIncludeCaller(include bool) *Logger {
+	l.mut.Lock()
+	l.includeCaller = include
+	l.mut.Unlock()
+	return l
+}
+
+func (l *Logger) IncludeCaller() bool {
+	if Config.IncludeCaller() {
+		return true
+	}
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+	return l.includeCaller
+}
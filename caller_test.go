@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func captureEngine(callers *[]Caller) WriteEngine {
+	return func(log *Logger, data *MessageData) {
+		*callers = append(*callers, data.Caller)
+	}
+}
+
+func currentLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func sendFromHelper(log *Logger) {
+	log.Info().CallerSkip(1).Send("helper")
+}
+
+func panicking(log *Logger) (line int) {
+	defer log.Recover()
+	line = currentLine() + 1
+	panic(errors.New("failure"))
+}
+
+func TestCaller(t *testing.T) {
+	var callers []Caller
+	log := New("app", captureEngine(&callers)).SetIncludeCaller(true)
+
+	var lines []int
+	lines = append(lines, currentLine()+1)
+	log.Info().Send("send")
+	lines = append(lines, currentLine()+1)
+	log.Error().Throw(errors.New("throw"))
+	lines = append(lines, currentLine()+1)
+	sendFromHelper(log)
+	lines = append(lines, panicking(log))
+	lines = append(lines, currentLine()+1)
+	log.StdLog(LevelInfo).Printf("std")
+	lines = append(lines, currentLine()+1)
+	log.Module("slog").Slog().Info("slog")
+
+	if len(callers) != len(lines) {
+		t.Fatalf("expected %d messages, got %d", len(lines), len(callers))
+	}
+	for i, caller := range callers {
+		if !strings.HasSuffix(caller.File, "caller_test.go") || caller.Line != lines[i] {
+			t.Errorf("message %d: expected caller_test.go:%d, got %s:%d (%s)", i, lines[i], caller.File, caller.Line, caller.Function)
+		}
+	}
+	if short := string(callers[0].AppendShort(nil)); !strings.HasSuffix(short, "/caller_test.go:"+strconv.Itoa(lines[0])) {
+		t.Errorf("unexpected short caller: %s", short)
+	}
+}
@@ -0,0 +1,93 @@
+package golog
+
+import (
+	"context"
+	"sync"
+)
+
+type loggerContextKey struct{}
+
+type paramsContextKey struct{}
+
+var defaultLogger = struct {
+	mut sync.RWMutex
+	log *Logger
+}{}
+
+// Default returns the logger used by FromContext when the context has none.
+// Unless changed with SetDefault, it is a logger named "app" writing to ColorEngine.
+func Default() *Logger {
+	defaultLogger.mut.RLock()
+	log := defaultLogger.log
+	defaultLogger.mut.RUnlock()
+	if log != nil {
+		return log
+	}
+	defaultLogger.mut.Lock()
+	defer defaultLogger.mut.Unlock()
+	if defaultLogger.log == nil {
+		defaultLogger.log = New("app")
+	}
+	return defaultLogger.log
+}
+
+func SetDefault(log *Logger) {
+	defaultLogger.mut.Lock()
+	defaultLogger.log = log
+	defaultLogger.mut.Unlock()
+}
+
+// WithLogger returns a copy of ctx carrying log.
+func WithLogger(ctx context.Context, log *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, log)
+}
+
+// FromContext returns the logger stored in ctx by WithLogger (or Default if there is none),
+// bound to ctx, so every message it creates carries parameters added by WithParams.
+func FromContext(ctx context.Context) *Logger {
+	log, ok := ctx.Value(loggerContextKey{}).(*Logger)
+	if !ok {
+		log = Default()
+	}
+	return log.withContext(ctx)
+}
+
+// WithParams returns a copy of ctx with params added to the request-scoped parameters.
+// They are written by engines for every message sent with this context.
+func WithParams(ctx context.Context, params ...Parameter) context.Context {
+	current := ContextParams(ctx)
+	merged := make([]Parameter, 0, len(current)+len(params))
+	merged = append(merged, current...)
+	merged = append(merged, params...)
+	return context.WithValue(ctx, paramsContextKey{}, merged)
+}
+
+// ContextParams returns parameters added to ctx by WithParams. ctx can be nil.
+func ContextParams(ctx context.Context) []Parameter {
+	if ctx == nil {
+		return nil
+	}
+	params, _ := ctx.Value(paramsContextKey{}).([]Parameter)
+	return params
+}
+
+func (l *Logger) withContext(ctx context.Context) *Logger {
+	cpy := l.snapshot()
+	cpy.ctx = ctx
+	return cpy
+}
+
+// Context returns the context bound to the logger by FromContext. It can be nil.
+func (l *Logger) Context() context.Context {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+	return l.ctx
+}
+
+// Ctx attaches ctx to the message, so engines can read its parameters, traces, etc.
+func (m Message) Ctx(ctx context.Context) Message {
+	if m.data != nil {
+		m.data.Context = ctx
+	}
+	return m
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestContextParams(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff))
+	log.Param("service", "api")
+
+	ctx := WithLogger(context.Background(), log)
+	ctx = WithParams(ctx, Parameter{Name: "request_id", Value: "abc"})
+	ctx = WithParams(ctx, Parameter{Name: "user_id", Value: 7})
+
+	FromContext(ctx).Module("db").Info().Send("query")
+	log.Warn().Ctx(ctx).Send("explicit")
+	log.Info().Send("without context")
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for i, expected := range []int{3, 3, 1} {
+		if len(lines[i].Context) != expected {
+			t.Errorf("line %d: expected %d context params, got %v", i, expected, lines[i].Context)
+		}
+	}
+	if lines[0].Context[1].Name != "request_id" || lines[0].Context[2].Name != "user_id" {
+		t.Errorf("unexpected context params: %v", lines[0].Context)
+	}
+}
+
+func TestTraceInExtractor(t *testing.T) {
+	Config.SetTraceExtractor(func(ctx context.Context) (TraceInfo, bool) {
+		return TraceInfo{TraceID: [16]byte{0xab, 0xcd}, SpanID: [8]byte{1}, Flags: 1}, true
+	})
+	defer Config.SetTraceExtractor(nil)
+
+	buff := new(bytes.Buffer)
+	New("app", JSONEngine(buff)).Info().Ctx(context.Background()).Send("traced")
+	var line struct {
+		TraceID    string `json:"trace_id"`
+		SpanID     string `json:"span_id"`
+		TraceFlags string `json:"trace_flags"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.TraceID != "abcd0000000000000000000000000000" || line.SpanID != "0100000000000000" || line.TraceFlags != "01" {
+		t.Errorf("unexpected trace: %+v", line)
+	}
+}
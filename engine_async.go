@@ -0,0 +1,226 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what AsyncEngine does with a message when its queue is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock makes the caller wait until there is space in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the message that is being sent.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest queued message to make space for the new one.
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops messages less severe than AsyncOptions.DropLevel and blocks for the rest.
+	OverflowDropBelowLevel
+)
+
+type AsyncOptions struct {
+	// QueueSize is the number of messages that can wait for the engine. Defaults to 1024.
+	QueueSize int
+	Overflow  OverflowPolicy
+	// DropLevel is used by OverflowDropBelowLevel. Defaults to LevelWarning.
+	DropLevel Level
+}
+
+type asyncItem struct {
+	log   *Logger
+	data  *MessageData
+	flush chan struct{}
+}
+
+// AsyncEngine runs a WriteEngine on a background goroutine, so slow engines do not stall the caller.
+//
+// Messages are copied before they are queued, except for Details, which are shared with the caller
+// and must not be modified after Send.
+type AsyncEngine struct {
+	engine    WriteEngine
+	managed   WriteEngine
+	opts      AsyncOptions
+	queue     chan asyncItem
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   atomic.Uint64
+}
+
+// Async wraps engine with a bounded queue processed by a background worker.
+// Use AsyncEngine.Engine to pass it to New. Queued messages are drained by Flush, Shutdown and Logger.Close.
+//
+// With OverflowBlock (and OverflowDropBelowLevel), an engine that logs through the same AsyncEngine blocks
+// its own worker once the queue is full, so such engines should use a dropping policy.
+func Async(engine WriteEngine, opts ...AsyncOptions) *AsyncEngine {
+	a := &AsyncEngine{
+		engine: engine,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if len(opts) > 0 {
+		a.opts = opts[0]
+	}
+	if a.opts.QueueSize <= 0 {
+		a.opts.QueueSize = 1024
+	}
+	if a.opts.DropLevel == 0 {
+		a.opts.DropLevel = LevelWarning
+	}
+	a.queue = make(chan asyncItem, a.opts.QueueSize)
+	a.managed = managedWrapper(engine, a.write, EngineHooks{
+		Flush: a.Flush,
+		Close: a.Close,
+	})
+	go a.run()
+	return a
+}
+
+// Engine returns the WriteEngine that enqueues messages.
+func (a *AsyncEngine) Engine() WriteEngine {
+	return a.managed
+}
+
+// Dropped returns the number of messages dropped because the queue was full or the engine was closed.
+func (a *AsyncEngine) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// run processes the queue until Close, then writes messages queued before it and exits.
+func (a *AsyncEngine) run() {
+	defer close(a.done)
+	for {
+		select {
+		case item := <-a.queue:
+			a.process(item)
+		case <-a.stop:
+			for {
+				select {
+				case item := <-a.queue:
+					a.process(item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AsyncEngine) process(item asyncItem) {
+	if item.flush != nil {
+		close(item.flush)
+		return
+	}
+	a.engine(item.log, item.data)
+	dataPool.Put(item.data)
+}
+
+func (a *AsyncEngine) closed() bool {
+	select {
+	case <-a.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// write never holds a lock while it waits for the queue, so the wrapped engine can log through a and Close
+// does not wait for blocked writers.
+func (a *AsyncEngine) write(log *Logger, data *MessageData) {
+	if a.closed() {
+		a.dropped.Add(1)
+		return
+	}
+	cpy := dataPool.Get()
+	copyMessageData(cpy, data)
+	item := asyncItem{log: log.snapshot(), data: cpy}
+	select {
+	case a.queue <- item:
+		return
+	default:
+	}
+	switch a.opts.Overflow {
+	case OverflowDropNewest:
+		a.drop(item)
+	case OverflowDropOldest:
+		a.pushDropOldest(item)
+	case OverflowDropBelowLevel:
+		if data.Level.Order() > a.opts.DropLevel.Order() {
+			a.drop(item)
+			return
+		}
+		a.push(item)
+	default:
+		a.push(item)
+	}
+}
+
+// push waits for space in the queue. Messages still waiting when the engine is closed are dropped.
+func (a *AsyncEngine) push(item asyncItem) {
+	select {
+	case a.queue <- item:
+	case <-a.stop:
+		a.drop(item)
+	}
+}
+
+// pushDropOldest queues item, dropping the oldest messages to make space. Flush markers are never dropped:
+// they are queued again after item, so Flush still waits for every message queued before it.
+func (a *AsyncEngine) pushDropOldest(item asyncItem) {
+	pending := []asyncItem{item}
+	for len(pending) > 0 {
+		select {
+		case a.queue <- pending[0]:
+			pending = pending[1:]
+		case old := <-a.queue:
+			if old.flush != nil {
+				pending = append(pending, old)
+			} else {
+				a.drop(old)
+			}
+		}
+	}
+}
+
+func (a *AsyncEngine) drop(item asyncItem) {
+	a.dropped.Add(1)
+	dataPool.Put(item.data)
+}
+
+// Flush waits until all messages queued so far are written by the wrapped engine.
+func (a *AsyncEngine) Flush(ctx context.Context) error {
+	if a.closed() {
+		return nil
+	}
+	marker := make(chan struct{})
+	select {
+	case a.queue <- asyncItem{flush: marker}:
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-marker:
+		return nil
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close drains the queue and stops the worker. Messages written after Close are dropped.
+func (a *AsyncEngine) Close(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		close(a.stop)
+	})
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,128 @@
+package golog
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncFlush(t *testing.T) {
+	var written atomic.Int32
+	async := Async(func(log *Logger, data *MessageData) {
+		if string(data.Message) != "message" || log.Modules()[0] != "app" {
+			t.Errorf("unexpected message %q from %v", data.Message, log.Modules())
+		}
+		written.Add(1)
+	})
+	log := New("app", async.Engine())
+	for range 100 {
+		log.Info().Send("message")
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if written.Load() != 100 {
+		t.Errorf("expected 100 messages, got %d", written.Load())
+	}
+	log.Info().Send("after close")
+	if async.Dropped() != 1 {
+		t.Errorf("expected 1 dropped message, got %d", async.Dropped())
+	}
+}
+
+func TestAsyncDropNewest(t *testing.T) {
+	release := make(chan struct{})
+	async := Async(func(log *Logger, data *MessageData) {
+		<-release
+	}, AsyncOptions{QueueSize: 1, Overflow: OverflowDropNewest})
+	log := New("app", async.Engine())
+	for range 10 {
+		log.Info().Send("message")
+	}
+	close(release)
+	_ = async.Flush(context.Background())
+	// first message is processed by the worker, second waits in the queue
+	if dropped := async.Dropped(); dropped < 8 {
+		t.Errorf("expected at least 8 dropped messages, got %d", dropped)
+	}
+	_ = log.Close(context.Background())
+}
+
+func TestAsyncDropOldestKeepsFlush(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var written atomic.Int32
+	async := Async(func(log *Logger, data *MessageData) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		written.Add(1)
+	}, AsyncOptions{QueueSize: 1, Overflow: OverflowDropOldest})
+	log := New("app", async.Engine())
+	log.Info().Send("first")
+	<-started
+
+	flushed := make(chan error, 1)
+	go func() {
+		flushed <- async.Flush(context.Background())
+	}()
+	for len(async.queue) == 0 {
+		runtime.Gosched()
+	}
+	// the queue is full with the flush marker, which must not be dropped
+	log.Info().Send("second")
+	select {
+	case <-flushed:
+		t.Fatal("Flush returned before the first message was written")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-flushed; err != nil || written.Load() != 1 {
+		t.Errorf("unexpected flush: %v, %d written", err, written.Load())
+	}
+	_ = log.Close(context.Background())
+}
+
+func TestAsyncCloseWhileLogging(t *testing.T) {
+	release := make(chan struct{})
+	var log *Logger
+	async := Async(func(_ *Logger, data *MessageData) {
+		if string(data.Message) == "outer" {
+			<-release
+			log.Info().Send("inner")
+		}
+	}, AsyncOptions{QueueSize: 1})
+	log = New("app", async.Engine())
+	log.Info().Send("outer")
+	log.Info().Send("queued")
+	go log.Info().Send("blocked")
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- async.Close(context.Background())
+	}()
+	for !async.closed() {
+		runtime.Gosched()
+	}
+	close(release)
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close deadlocked")
+	}
+}
+
+func BenchmarkAsync(b *testing.B) {
+	async := Async(ColorEngine(io.Discard), AsyncOptions{Overflow: OverflowDropNewest})
+	log := New("test", async.Engine())
+	b.Run("JustMessage", runJustMessage(log))
+	_ = log.Close(context.Background())
+}
@@ -0,0 +1,312 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"time"
+	"unsafe"
+)
+
+// binaryFormat appends values in a self-describing binary encoding (CBOR or MessagePack).
+type binaryFormat interface {
+	appendArray(dst []byte, n int) []byte
+	appendMap(dst []byte, n int) []byte
+	appendString(dst []byte, s string) []byte
+	appendStringHeader(dst []byte, n int) []byte
+	appendBytes(dst []byte, b []byte) []byte
+	appendInt(dst []byte, v int64) []byte
+	appendUint(dst []byte, v uint64) []byte
+	appendFloat(dst []byte, v float64) []byte
+	appendBool(dst []byte, v bool) []byte
+	appendNil(dst []byte) []byte
+	appendTime(dst []byte, t time.Time) []byte
+}
+
+// binaryEngine writes records prefixed with their length as a big-endian uint32.
+func binaryEngine[F binaryFormat](f F, writers []io.Writer) WriteEngine {
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+
+	writer := io.MultiWriter(writers...)
+
+	return func(log *Logger, data *MessageData) {
+		buff := byteBuffers.Get()
+		defer byteBuffers.Put(buff)
+		dst := append((*buff)[:0], 0, 0, 0, 0)
+		dst = appendBinaryRecord(f, dst, log, data)
+		binary.BigEndian.PutUint32(dst, uint32(len(dst)-4))
+		*buff = dst
+		_, _ = writer.Write(dst)
+	}
+}
+
+// appendBinaryRecord encodes the fields of JSONEngine as a map. The level is written as an integer (level) and
+// a name (level_name), the timestamp as a native time and params with native values where the format has them.
+func appendBinaryRecord[F binaryFormat](f F, dst []byte, log *Logger, data *MessageData) []byte {
+	trace, traced := data.Trace()
+	fields := 7
+	for _, present := range [...]bool{data.StackIncluded, data.Duration > 0, data.Details != nil, traced, traced, traced,
+		data.Caller.Defined(), data.Error != nil} {
+		if present {
+			fields++
+		}
+	}
+	dst = f.appendMap(dst, fields)
+	dst = f.appendString(dst, "timestamp")
+	dst = f.appendTime(dst, data.Time)
+	dst = f.appendString(dst, "level")
+	dst = f.appendInt(dst, int64(data.Level))
+	dst = f.appendString(dst, "level_name")
+	dst = f.appendString(dst, data.Level.String())
+	dst = f.appendString(dst, "context")
+	dst = appendBinaryParams(f, dst, data.LoggerParams(), data.ContextParams())
+	dst = f.appendString(dst, "module")
+	modules := log.Modules()
+	dst = f.appendArray(dst, len(modules))
+	for _, module := range modules {
+		dst = f.appendString(dst, module)
+	}
+	dst = f.appendString(dst, "params")
+	dst = appendBinaryParams(f, dst, data.Params, nil)
+	if data.StackIncluded {
+		dst = f.appendString(dst, "stack")
+		frames := data.Stack.Frames()
+		dst = f.appendArray(dst, len(frames))
+		for _, frame := range frames {
+			dst = appendBinaryFrame(f, dst, frame)
+		}
+	}
+	if data.Duration > 0 {
+		dst = f.appendString(dst, "duration")
+		dst = f.appendInt(dst, data.Duration.Milliseconds())
+	}
+	if data.Details != nil {
+		dst = f.appendString(dst, "details")
+		dst = appendBinaryAny(f, dst, data.Details)
+	}
+	dst = f.appendString(dst, "message")
+	dst = f.appendString(dst, unsafe.String(unsafe.SliceData(data.Message), len(data.Message)))
+	if traced {
+		dst = f.appendString(dst, "trace_id")
+		dst = trace.AppendTraceID(f.appendStringHeader(dst, 32))
+		dst = f.appendString(dst, "span_id")
+		dst = trace.AppendSpanID(f.appendStringHeader(dst, 16))
+		dst = f.appendString(dst, "trace_flags")
+		dst = trace.AppendFlags(f.appendStringHeader(dst, 2))
+	}
+	if data.Caller.Defined() {
+		dst = f.appendString(dst, "caller")
+		dst = appendBinaryFrame(f, dst, data.Caller)
+	}
+	if data.Error != nil {
+		dst = f.appendString(dst, "error")
+		dst = appendBinaryError(f, dst, NewErrorTree(data.Error))
+	}
+	return dst
+}
+
+// appendBinaryParams encodes both lists as a single array of {name, value} maps.
+func appendBinaryParams[F binaryFormat](f F, dst []byte, params, more []Parameter) []byte {
+	dst = f.appendArray(dst, len(params)+len(more))
+	for _, list := range [2][]Parameter{params, more} {
+		for _, p := range list {
+			dst = f.appendMap(dst, 2)
+			dst = f.appendString(dst, "name")
+			dst = f.appendString(dst, p.Name)
+			dst = f.appendString(dst, "value")
+			dst = appendBinaryParam(f, dst, p)
+		}
+	}
+	return dst
+}
+
+// appendBinaryParam encodes the value like AppendJSON, but with native integers, floats and times.
+func appendBinaryParam[F binaryFormat](f F, dst []byte, p Parameter) []byte {
+	switch p.kind {
+	case KindString, KindBytes:
+		return f.appendString(dst, p.str)
+	case KindInt:
+		return f.appendInt(dst, p.Int64())
+	case KindUint:
+		return f.appendUint(dst, p.Uint64())
+	case KindFloat:
+		return f.appendFloat(dst, p.Float64())
+	case KindBool:
+		return f.appendBool(dst, p.Bool())
+	case KindDuration:
+		return appendBinaryDuration(f, dst, p.Duration())
+	case KindTime:
+		return f.appendTime(dst, p.Time())
+	case KindError, KindStringer:
+		if p.Value == nil {
+			return f.appendNil(dst)
+		}
+		return f.appendString(dst, p.Text())
+	}
+	return appendBinaryAny(f, dst, p.Value)
+}
+
+// appendBinaryDuration encodes the duration text as a string. The text is appended first and moved
+// after the header, which is at most 9 bytes long.
+func appendBinaryDuration[F binaryFormat](f F, dst []byte, d time.Duration) []byte {
+	start := len(dst)
+	dst = appendDuration(dst, d)
+	n := len(dst) - start
+	dst = f.appendStringHeader(dst, n)
+	var header [9]byte
+	h := copy(header[:], dst[start+n:])
+	copy(dst[start+h:], dst[start:start+n])
+	copy(dst[start:], header[:h])
+	return dst
+}
+
+func appendBinaryFrame[F binaryFormat](f F, dst []byte, frame Frame) []byte {
+	dst = f.appendMap(dst, 3)
+	dst = f.appendString(dst, "function")
+	dst = f.appendString(dst, frame.Function)
+	dst = f.appendString(dst, "file")
+	dst = f.appendString(dst, frame.File)
+	dst = f.appendString(dst, "line")
+	return f.appendInt(dst, int64(frame.Line))
+}
+
+func appendBinaryError[F binaryFormat](f F, dst []byte, tree *ErrorTree) []byte {
+	fields := 2
+	for _, present := range [...]bool{len(tree.Params) > 0, len(tree.Stack) > 0, len(tree.Causes) > 0} {
+		if present {
+			fields++
+		}
+	}
+	dst = f.appendMap(dst, fields)
+	dst = f.appendString(dst, "message")
+	dst = f.appendString(dst, tree.Message)
+	dst = f.appendString(dst, "type")
+	dst = f.appendString(dst, tree.Type)
+	if len(tree.Params) > 0 {
+		dst = f.appendString(dst, "params")
+		dst = appendBinaryParams(f, dst, tree.Params, nil)
+	}
+	if len(tree.Stack) > 0 {
+		dst = f.appendString(dst, "stack")
+		dst = f.appendArray(dst, len(tree.Stack))
+		for _, frame := range tree.Stack {
+			dst = appendBinaryFrame(f, dst, frame)
+		}
+	}
+	if len(tree.Causes) > 0 {
+		dst = f.appendString(dst, "causes")
+		dst = f.appendArray(dst, len(tree.Causes))
+		for i := range tree.Causes {
+			dst = appendBinaryError(f, dst, &tree.Causes[i])
+		}
+	}
+	return dst
+}
+
+// maxBinaryDepth limits the nesting of []any and map[string]any encoded directly. Deeper values are converted
+// through their JSON encoding, which reports cycles.
+const maxBinaryDepth = 32
+
+// appendBinaryAny encodes common types directly, with sorted map keys. Other values are converted through
+// their JSON encoding; values that cannot be encoded are written as "!error: ..." strings like in JSONEngine.
+func appendBinaryAny[F binaryFormat](f F, dst []byte, value any) []byte {
+	return appendBinaryValue(f, dst, value, 0)
+}
+
+func appendBinaryValue[F binaryFormat](f F, dst []byte, value any, depth int) []byte {
+	switch v := value.(type) {
+	case nil:
+		return f.appendNil(dst)
+	case string:
+		return f.appendString(dst, v)
+	case []byte:
+		return f.appendBytes(dst, v)
+	case bool:
+		return f.appendBool(dst, v)
+	case int:
+		return f.appendInt(dst, int64(v))
+	case int8:
+		return f.appendInt(dst, int64(v))
+	case int16:
+		return f.appendInt(dst, int64(v))
+	case int32:
+		return f.appendInt(dst, int64(v))
+	case int64:
+		return f.appendInt(dst, v)
+	case uint:
+		return f.appendUint(dst, uint64(v))
+	case uint8:
+		return f.appendUint(dst, uint64(v))
+	case uint16:
+		return f.appendUint(dst, uint64(v))
+	case uint32:
+		return f.appendUint(dst, uint64(v))
+	case uint64:
+		return f.appendUint(dst, v)
+	case float32:
+		return f.appendFloat(dst, float64(v))
+	case float64:
+		return f.appendFloat(dst, v)
+	case time.Time:
+		return f.appendTime(dst, v)
+	case time.Duration:
+		return appendBinaryDuration(f, dst, v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return f.appendInt(dst, n)
+		}
+		if n, err := v.Float64(); err == nil && !math.IsInf(n, 0) {
+			return f.appendFloat(dst, n)
+		}
+		return f.appendString(dst, v.String())
+	case []string:
+		dst = f.appendArray(dst, len(v))
+		for _, s := range v {
+			dst = f.appendString(dst, s)
+		}
+		return dst
+	case []any:
+		if depth < maxBinaryDepth {
+			dst = f.appendArray(dst, len(v))
+			for _, item := range v {
+				dst = appendBinaryValue(f, dst, item, depth+1)
+			}
+			return dst
+		}
+	case map[string]any:
+		if depth < maxBinaryDepth {
+			dst = f.appendMap(dst, len(v))
+			for _, key := range slices.Sorted(maps.Keys(v)) {
+				dst = f.appendString(dst, key)
+				dst = appendBinaryValue(f, dst, v[key], depth+1)
+			}
+			return dst
+		}
+	case map[string]string:
+		dst = f.appendMap(dst, len(v))
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			dst = f.appendString(dst, key)
+			dst = f.appendString(dst, v[key])
+		}
+		return dst
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return f.appendString(dst, "!error: "+err.Error())
+	}
+	var decoded any
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err = dec.Decode(&decoded); err != nil {
+		return f.appendString(dst, "!error: "+err.Error())
+	}
+	// the decoded value has no cycles, so it does not need the depth limit
+	return appendBinaryValue(f, dst, decoded, math.MinInt)
+}
@@ -0,0 +1,104 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBinaryFormats(t *testing.T) {
+	at := time.Unix(1700000000, 500)
+	for _, test := range []struct {
+		name   string
+		encode func(f binaryFormat, dst []byte) []byte
+		cbor   string
+		msgp   string
+	}{
+		{"small int", func(f binaryFormat, dst []byte) []byte { return f.appendInt(dst, 10) }, "0a", "0a"},
+		{"negative int", func(f binaryFormat, dst []byte) []byte { return f.appendInt(dst, -500) }, "3901f3", "d1fe0c"},
+		{"uint64", func(f binaryFormat, dst []byte) []byte { return f.appendUint(dst, math.MaxUint64) },
+			"1bffffffffffffffff", "cfffffffffffffffff"},
+		{"string", func(f binaryFormat, dst []byte) []byte { return f.appendString(dst, "abc") }, "63616263", "a3616263"},
+		{"bytes", func(f binaryFormat, dst []byte) []byte { return f.appendBytes(dst, []byte{1}) }, "4101", "c40101"},
+		{"float", func(f binaryFormat, dst []byte) []byte { return f.appendFloat(dst, 1.5) },
+			"fb3ff8000000000000", "cb3ff8000000000000"},
+		{"map", func(f binaryFormat, dst []byte) []byte { return f.appendNil(f.appendBool(f.appendMap(dst, 1), true)) },
+			"a1f5f6", "81c3c0"},
+		{"array", func(f binaryFormat, dst []byte) []byte { return f.appendArray(dst, 20) }, "94", "dc0014"},
+		{"time", func(f binaryFormat, dst []byte) []byte { return f.appendTime(dst, at) },
+			"d903e9a2011a6553f100281901f4", "c70cff000001f4000000006553f100"},
+	} {
+		if got := hex.EncodeToString(test.encode(cborFormat{}, nil)); got != test.cbor {
+			t.Errorf("%s: expected CBOR %s, got %s", test.name, test.cbor, got)
+		}
+		if got := hex.EncodeToString(test.encode(msgpackFormat{}, nil)); got != test.msgp {
+			t.Errorf("%s: expected MessagePack %s, got %s", test.name, test.msgp, got)
+		}
+	}
+}
+
+func TestBinaryAny(t *testing.T) {
+	type user struct {
+		ID int `json:"id"`
+	}
+	for value, expected := range map[any]string{
+		user{ID: 5}:          "a1626964" + "05",
+		time.Second:          "62" + "3173",
+		[2]bool{true, false}: "82f5f4",
+	} {
+		if got := hex.EncodeToString(appendBinaryAny(cborFormat{}, nil, value)); got != expected {
+			t.Errorf("%T: expected %s, got %s", value, expected, got)
+		}
+	}
+	if got := string(appendBinaryAny(cborFormat{}, nil, make(chan int))[2:]); got != "!error: json: unsupported type: chan int" {
+		t.Errorf("unexpected fallback: %s", got)
+	}
+}
+
+func TestBinaryAnyCycle(t *testing.T) {
+	cyclic := map[string]any{"a": 1}
+	cyclic["self"] = cyclic
+	list := []any{1}
+	list = append(list, list)
+	list[1] = list
+	for _, value := range []any{cyclic, list} {
+		if got := appendBinaryAny(cborFormat{}, nil, value); !bytes.Contains(got, []byte("!error: json: unsupported value: encountered a cycle")) {
+			t.Errorf("%T: unexpected encoding %q", value, got)
+		}
+	}
+
+	deep := map[string]any{"leaf": true}
+	for range maxBinaryDepth * 2 {
+		deep = map[string]any{"next": []any{deep}}
+	}
+	if got := appendBinaryAny(msgpackFormat{}, nil, deep); bytes.Contains(got, []byte("!error")) || !bytes.Contains(got, []byte("leaf")) {
+		t.Errorf("deep value not encoded: %q", got)
+	}
+}
+
+func BenchmarkCBOR(b *testing.B) {
+	benchmarkBinary(b, New("test", CBOREngine(io.Discard)))
+}
+
+func BenchmarkMsgpack(b *testing.B) {
+	benchmarkBinary(b, New("test", MsgpackEngine(io.Discard)))
+}
+
+func benchmarkBinary(b *testing.B, log *Logger) {
+	b.Run("JustMessage", runJustMessage(log))
+	b.Run("Modules", func(b *testing.B) {
+		b.Run("1", runWithModules(log, 1))
+		b.Run("5", runWithModules(log, 5))
+		b.Run("20", runWithModules(log, 20))
+	})
+	b.Run("WithDetails", runWithDetails(log))
+	b.Run("WithParams", runWithParams(log))
+	b.Run("UserMessage", func(b *testing.B) {
+		b.Run("10", runUserMessage(log, 10))
+		b.Run("100", runUserMessage(log, 100))
+		b.Run("1600", runUserMessage(log, 1600))
+	})
+}
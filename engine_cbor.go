@@ -0,0 +1,97 @@
+package golog
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// CBOREngine writes messages as CBOR (RFC 8949) maps with the fields of JSONEngine. Each record is prefixed
+// with its length as a big-endian uint32; package logdecode reads them back. Timestamps use tag 1001
+// (extended time, RFC 9581) with nanosecond precision.
+func CBOREngine(writers ...io.Writer) WriteEngine {
+	return binaryEngine(cborFormat{}, writers)
+}
+
+type cborFormat struct{}
+
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborString = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+)
+
+func appendCBORHeader(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+}
+
+func (cborFormat) appendArray(dst []byte, n int) []byte {
+	return appendCBORHeader(dst, cborArray, uint64(n))
+}
+
+func (cborFormat) appendMap(dst []byte, n int) []byte {
+	return appendCBORHeader(dst, cborMap, uint64(n))
+}
+
+func (cborFormat) appendString(dst []byte, s string) []byte {
+	return append(appendCBORHeader(dst, cborString, uint64(len(s))), s...)
+}
+
+func (cborFormat) appendStringHeader(dst []byte, n int) []byte {
+	return appendCBORHeader(dst, cborString, uint64(n))
+}
+
+func (cborFormat) appendBytes(dst []byte, b []byte) []byte {
+	return append(appendCBORHeader(dst, cborBytes, uint64(len(b))), b...)
+}
+
+func (cborFormat) appendInt(dst []byte, v int64) []byte {
+	if v < 0 {
+		return appendCBORHeader(dst, cborNegInt, uint64(^v))
+	}
+	return appendCBORHeader(dst, cborUint, uint64(v))
+}
+
+func (cborFormat) appendUint(dst []byte, v uint64) []byte {
+	return appendCBORHeader(dst, cborUint, v)
+}
+
+func (cborFormat) appendFloat(dst []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(dst, 0xfb), math.Float64bits(v))
+}
+
+func (cborFormat) appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 0xf5)
+	}
+	return append(dst, 0xf4)
+}
+
+func (cborFormat) appendNil(dst []byte) []byte {
+	return append(dst, 0xf6)
+}
+
+// appendTime writes tag 1001 with a map of integer seconds (key 1) and nanoseconds (key -9).
+func (f cborFormat) appendTime(dst []byte, t time.Time) []byte {
+	dst = appendCBORHeader(dst, cborTag, 1001)
+	dst = f.appendMap(dst, 2)
+	dst = f.appendInt(dst, 1)
+	dst = f.appendInt(dst, t.Unix())
+	dst = f.appendInt(dst, -9)
+	return f.appendInt(dst, int64(t.Nanosecond()))
+}
@@ -32,7 +32,7 @@ func ColorEngine(writers ...io.Writer) WriteEngine {
 		}
 		{
 			dateBuff := dateTimeBuffer.Get()
-			*dateBuff = time.Now().AppendFormat(*dateBuff, log.DateTimeFormat("02.01.2006 15:04:05"))
+			*dateBuff = data.Time.AppendFormat(*dateBuff, log.DateTimeFormat("02.01.2006 15:04:05"))
 			buff.Write(*dateBuff)
 			dateTimeBuffer.Put(dateBuff)
 		}
@@ -43,14 +43,27 @@ func ColorEngine(writers ...io.Writer) WriteEngine {
 			buff.WriteString(module)
 			buff.WriteByte(' ')
 		}
+		if data.Caller.Defined() {
+			buff.WriteString("| ")
+			buff.Write(data.Caller.AppendShort(buff.AvailableBuffer()))
+			buff.WriteByte(' ')
+		}
 		{
-			params := log.Params()
-			if len(params) > 0 {
+			params := data.LoggerParams()
+			ctxParams := data.ContextParams()
+			if len(params) > 0 || len(ctxParams) > 0 {
 				buff.WriteString("| ")
 				for _, parameter := range params {
 					buff.WriteString(parameter.Name)
 					buff.WriteByte('(')
-					_, _ = fmt.Fprint(buff, parameter.Value)
+					buff.Write(parameter.AppendText(buff.AvailableBuffer()))
+					buff.WriteByte(')')
+					buff.WriteByte(' ')
+				}
+				for _, parameter := range ctxParams {
+					buff.WriteString(parameter.Name)
+					buff.WriteByte('(')
+					buff.Write(parameter.AppendText(buff.AvailableBuffer()))
 					buff.WriteByte(')')
 					buff.WriteByte(' ')
 				}
@@ -62,12 +75,18 @@ func ColorEngine(writers ...io.Writer) WriteEngine {
 				for _, p := range data.Params {
 					buff.WriteString(p.Name)
 					buff.WriteByte('(')
-					_, _ = fmt.Fprint(buff, p.Value)
+					buff.Write(p.AppendText(buff.AvailableBuffer()))
 					buff.WriteByte(')')
 					buff.WriteByte(' ')
 				}
 			}
 		}
+		if trace, ok := data.Trace(); ok {
+			var traceID [32]byte
+			buff.WriteString("| trace(")
+			buff.Write(trace.AppendTraceID(traceID[:0])[:8])
+			buff.WriteString(") ")
+		}
 		{
 			if data.Duration > 0 {
 				buff.WriteString("| ")
@@ -83,12 +102,7 @@ func ColorEngine(writers ...io.Writer) WriteEngine {
 				if !Config.MarshalDetails() {
 					_, _ = fmt.Fprint(buff, data.Details)
 				} else {
-					enc := encoders.Get()
-					defer encoders.Put(enc)
-					enc.buff.Reset()
-					_ = enc.json.Encode(data.Details)
-					buff.Write(enc.buff.Bytes())
-					buff.Truncate(buff.Len() - 1)
+					buff.Write(appendJSONValue(buff.AvailableBuffer(), data.Details))
 				}
 			}
 		}
@@ -96,12 +110,12 @@ func ColorEngine(writers ...io.Writer) WriteEngine {
 			buff.WriteString(color.ResetSet)
 		}
 		buff.WriteByte('\n')
+		if data.Error != nil {
+			buff.Write(NewErrorTree(data.Error).AppendText(buff.AvailableBuffer()))
+		}
 		if data.StackIncluded {
-			buff.Write(data.Stack)
+			buff.Write(data.Stack.AppendText(buff.AvailableBuffer()))
 		}
 		_, _ = writer.Write(buff.Bytes())
-		if data.ExitCode != 0 {
-			os.Exit(data.ExitCode)
-		}
 	}
 }
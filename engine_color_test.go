@@ -21,6 +21,7 @@ func BenchmarkColor(b *testing.B) {
 		b.Run("ParseToJSON=false", runWithDetails(log))
 		Config.SetMarshalDetails(true)
 	})
+	b.Run("WithParams", runWithParams(log))
 	b.Run("UserMessage", func(b *testing.B) {
 		b.Run("10", runUserMessage(log, 10))
 		b.Run("25", runUserMessage(log, 25))
@@ -0,0 +1,173 @@
+package golog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type dedupEntry struct {
+	log      *Logger
+	data     *MessageData
+	first    time.Time
+	last     time.Time
+	repeated int
+}
+
+type deduplicator struct {
+	mut     sync.Mutex
+	engine  WriteEngine
+	window  time.Duration
+	entries map[string]*dedupEntry
+	timer   *time.Timer
+	key     []byte
+}
+
+// Deduplicate wraps engine, so identical messages (same level, modules, message, logger, context and message params) sent within window
+// are written once. When the window ends, a single message saying how many times it was repeated is written.
+// Window defaults to 10 seconds.
+func Deduplicate(engine WriteEngine, window ...time.Duration) WriteEngine {
+	d := &deduplicator{
+		engine:  engine,
+		window:  10 * time.Second,
+		entries: map[string]*dedupEntry{},
+	}
+	if len(window) > 0 && window[0] > 0 {
+		d.window = window[0]
+	}
+	return managedWrapper(engine, d.write, EngineHooks{
+		Flush: d.flush,
+		Close: d.close,
+	})
+}
+
+// appendKey identifies the message by its level, module path, text and logger, context and message params.
+// Every field is followed by its length, so different messages never share a key.
+func (d *deduplicator) appendKey(dst []byte, log *Logger, data *MessageData) []byte {
+	start := len(dst)
+	dst = endKeyField(strconv.AppendUint(dst, uint64(data.Level), 10), start)
+	start = len(dst)
+	dst = endKeyField(append(dst, log.ModulePath()...), start)
+	start = len(dst)
+	dst = endKeyField(append(dst, data.Message...), start)
+	for _, params := range [3][]Parameter{data.LoggerParams(), data.ContextParams(), data.Params} {
+		start = len(dst)
+		dst = endKeyField(strconv.AppendInt(dst, int64(len(params)), 10), start)
+		for _, param := range params {
+			start = len(dst)
+			dst = endKeyField(append(dst, param.Name...), start)
+			start = len(dst)
+			dst = endKeyField(param.AppendText(dst), start)
+		}
+	}
+	return dst
+}
+
+// endKeyField appends the length of the field written since start.
+func endKeyField(dst []byte, start int) []byte {
+	dst = append(dst, 0)
+	dst = strconv.AppendInt(dst, int64(len(dst)-start-1), 10)
+	return append(dst, 0)
+}
+
+func (d *deduplicator) write(log *Logger, data *MessageData) {
+	now := time.Now()
+	d.mut.Lock()
+	d.key = d.appendKey(d.key[:0], log, data)
+	entry, ok := d.entries[string(d.key)]
+	if ok && now.Sub(entry.first) < d.window {
+		entry.repeated++
+		entry.last = now
+		d.mut.Unlock()
+		return
+	}
+	if ok {
+		delete(d.entries, string(d.key))
+	}
+	cpy := dataPool.Get()
+	copyMessageData(cpy, data)
+	d.entries[string(d.key)] = &dedupEntry{
+		log:   log.snapshot(),
+		data:  cpy,
+		first: now,
+		last:  now,
+	}
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.window, d.tick)
+	}
+	d.mut.Unlock()
+
+	if ok {
+		d.report(entry)
+	}
+	d.engine(log, data)
+}
+
+// expire removes entries with window ending before deadline and schedules the next check. d.mut must be held.
+func (d *deduplicator) expire(deadline time.Time) []*dedupEntry {
+	var expired []*dedupEntry
+	var next time.Time
+	for key, entry := range d.entries {
+		end := entry.first.Add(d.window)
+		if !end.After(deadline) {
+			expired = append(expired, entry)
+			delete(d.entries, key)
+		} else if next.IsZero() || end.Before(next) {
+			next = end
+		}
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if !next.IsZero() {
+		d.timer = time.AfterFunc(time.Until(next), d.tick)
+	}
+	return expired
+}
+
+func (d *deduplicator) tick() {
+	d.mut.Lock()
+	expired := d.expire(time.Now())
+	d.mut.Unlock()
+	for _, entry := range expired {
+		d.report(entry)
+	}
+}
+
+// report writes how many times the entry was repeated (if at all) and releases its data.
+func (d *deduplicator) report(entry *dedupEntry) {
+	defer dataPool.Put(entry.data)
+	if entry.repeated == 0 {
+		return
+	}
+	data := entry.data
+	data.Time = entry.last
+	data.Message = fmt.Appendf(data.Message, " (repeated %d times over %s)", entry.repeated, entry.last.Sub(entry.first).Round(time.Millisecond))
+	data.Params = append(data.Params, Int("repeated", entry.repeated))
+	data.StackIncluded = false
+	data.ExitCode = 0
+	d.engine(entry.log, data)
+}
+
+func (d *deduplicator) flush(_ context.Context) error {
+	d.mut.Lock()
+	expired := d.expire(time.Now().Add(d.window))
+	d.mut.Unlock()
+	for _, entry := range expired {
+		d.report(entry)
+	}
+	return nil
+}
+
+func (d *deduplicator) close(_ context.Context) error {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeduplicate(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", Deduplicate(JSONEngine(buff), time.Hour))
+	for range 50 {
+		log.Error().Param("host", "db-1").Send("cannot connect to %s", "db-1")
+	}
+	log.Error().Param("host", "db-2").Send("cannot connect to %s", "db-2")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	summary := lines[2]
+	if !strings.HasPrefix(summary.Message, "cannot connect to db-1 (repeated 49 times over") {
+		t.Errorf("unexpected summary: %q", summary.Message)
+	}
+	if len(summary.Params) != 2 || summary.Params[1].Name != "repeated" || summary.Params[1].Value != float64(49) {
+		t.Errorf("unexpected summary params: %v", summary.Params)
+	}
+}
+
+func TestDeduplicateLoggerAndContextParams(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", Deduplicate(JSONEngine(buff), time.Hour))
+	log.With(Str("request", "a")).Info().Send("done")
+	log.With(Str("request", "b")).Info().Send("done")
+	log.Info().Ctx(WithParams(context.Background(), Str("user", "a"))).Send("done")
+	log.Info().Ctx(WithParams(context.Background(), Str("user", "b"))).Send("done")
+	// the same text split differently between fields
+	log.Info().Param("a", "b|c").Send("done")
+	log.Info().Param("a|b", "c").Send("done")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if lines := decodeLines(t, buff); len(lines) != 6 {
+		t.Fatalf("expected 6 lines, got %d", len(lines))
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"time"
 	"unsafe"
 
@@ -17,36 +18,14 @@ type encoder struct {
 	buff *bytes.Buffer
 }
 
-type structure struct {
-	Timestamp string      `json:"timestamp"`
-	Level     string      `json:"level"`
-	Context   []Parameter `json:"context"`
-	Module    []string    `json:"module"`
-	Params    []Parameter `json:"params"`
-	Stack     string      `json:"stack,omitempty"`
-	Duration  int64       `json:"duration,omitempty"`
-	Details   any         `json:"details,omitempty"`
-	Message   string      `json:"message"`
-}
-
-var structures = gpool.New[structure](gpool.OnPut(func(s *structure) {
-	clear(s.Module)
-	clear(s.Params)
-	s.Timestamp = ""
-	s.Level = ""
-	s.Module = nil
-	s.Context = nil
-	s.Stack = ""
-	s.Duration = 0
-	s.Details = nil
-	s.Message = ""
-}))
-
 var encoders = gpool.New[encoder](gpool.OnInit[encoder](func(e *encoder) {
 	e.buff = bytes.NewBuffer(make([]byte, 0, Config.EngineBufferSize()))
 	e.json = json.NewEncoder(e.buff)
 }))
 
+// JSONEngine writes messages as JSON lines. Fields are appended directly to a pooled buffer;
+// encoding/json is used only for params and details of types without a fast path.
+// Values that cannot be encoded are written as "!error: ..." strings.
 func JSONEngine(writers ...io.Writer) WriteEngine {
 	if len(writers) == 0 {
 		writers = append(writers, os.Stdout)
@@ -55,31 +34,180 @@ func JSONEngine(writers ...io.Writer) WriteEngine {
 	writer := io.MultiWriter(writers...)
 
 	return func(log *Logger, data *MessageData) {
-		struc := structures.Get()
-		defer structures.Put(struc)
+		buff := byteBuffers.Get()
+		defer byteBuffers.Put(buff)
+		*buff = appendJSONRecord((*buff)[:0], log, data)
+		_, _ = writer.Write(*buff)
+	}
+}
 
+func appendJSONRecord(dst []byte, log *Logger, data *MessageData) []byte {
+	dst = append(dst, `{"timestamp":`...)
+	{
 		dateTimeBuff := dateTimeBuffer.Get()
-		defer dateTimeBuffer.Put(dateTimeBuff)
-		*dateTimeBuff = time.Now().AppendFormat(*dateTimeBuff, log.DateTimeFormat(time.RFC3339Nano))
-		struc.Timestamp = unsafe.String(unsafe.SliceData(*dateTimeBuff), len(*dateTimeBuff))
-		struc.Message = unsafe.String(unsafe.SliceData(data.Message), len(data.Message))
-		struc.Level = data.Level.String()
-		struc.Details = data.Details
-		if data.StackIncluded {
-			struc.Stack = unsafe.String(unsafe.SliceData(data.Stack), len(data.Stack))
+		*dateTimeBuff = data.Time.AppendFormat(*dateTimeBuff, log.DateTimeFormat(time.RFC3339Nano))
+		dst = appendJSONString(dst, unsafe.String(unsafe.SliceData(*dateTimeBuff), len(*dateTimeBuff)))
+		dateTimeBuffer.Put(dateTimeBuff)
+	}
+	dst = append(dst, `,"level":`...)
+	dst = appendJSONString(dst, data.Level.String())
+	dst = append(dst, `,"context":`...)
+	dst = appendParamsJSON(dst, data.LoggerParams(), data.ContextParams())
+	dst = append(dst, `,"module":`...)
+	if modules := log.Modules(); modules == nil {
+		dst = append(dst, "null"...)
+	} else {
+		dst = append(dst, '[')
+		for i, module := range modules {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendJSONString(dst, module)
 		}
-		struc.Params = data.Params
-		if data.Duration > 0 {
-			struc.Duration = data.Duration.Milliseconds()
+		dst = append(dst, ']')
+	}
+	dst = append(dst, `,"params":`...)
+	dst = appendParamsJSON(dst, data.Params)
+	if data.StackIncluded {
+		if frames := data.Stack.Frames(); len(frames) > 0 {
+			dst = append(dst, `,"stack":`...)
+			dst = appendFramesJSON(dst, frames)
 		}
-		struc.Module = log.Modules()
-		struc.Context = log.Params()
-		enc := encoders.Get()
-		defer encoders.Put(enc)
-		enc.buff.Reset()
-		if err := enc.json.Encode(struc); err != nil {
-			panic(fmt.Errorf("failed to encode structure: %w", err))
+	}
+	if data.Duration.Milliseconds() > 0 {
+		dst = append(dst, `,"duration":`...)
+		dst = strconv.AppendInt(dst, data.Duration.Milliseconds(), 10)
+	}
+	if data.Details != nil {
+		dst = append(dst, `,"details":`...)
+		dst = appendJSONValue(dst, data.Details)
+	}
+	dst = append(dst, `,"message":`...)
+	dst = appendJSONString(dst, unsafe.String(unsafe.SliceData(data.Message), len(data.Message)))
+	if trace, ok := data.Trace(); ok {
+		dst = append(dst, `,"trace_id":"`...)
+		dst = trace.AppendTraceID(dst)
+		dst = append(dst, `","span_id":"`...)
+		dst = trace.AppendSpanID(dst)
+		dst = append(dst, `","trace_flags":"`...)
+		dst = trace.AppendFlags(dst)
+		dst = append(dst, '"')
+	}
+	if data.Caller.Defined() {
+		dst = append(dst, `,"caller":`...)
+		dst = appendFrameJSON(dst, data.Caller)
+	}
+	if data.Error != nil {
+		dst = append(dst, `,"error":`...)
+		dst = appendErrorTreeJSON(dst, NewErrorTree(data.Error))
+	}
+	return append(dst, '}', '\n')
+}
+
+func appendFrameJSON(dst []byte, frame Frame) []byte {
+	dst = append(dst, `{"function":`...)
+	dst = appendJSONString(dst, frame.Function)
+	dst = append(dst, `,"file":`...)
+	dst = appendJSONString(dst, frame.File)
+	dst = append(dst, `,"line":`...)
+	dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+	return append(dst, '}')
+}
+
+func appendFramesJSON(dst []byte, frames []Frame) []byte {
+	dst = append(dst, '[')
+	for i, frame := range frames {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendFrameJSON(dst, frame)
+	}
+	return append(dst, ']')
+}
+
+// appendErrorTreeJSON encodes the tree like encoding/json does with its struct tags.
+func appendErrorTreeJSON(dst []byte, tree *ErrorTree) []byte {
+	dst = append(dst, `{"message":`...)
+	dst = appendJSONString(dst, tree.Message)
+	dst = append(dst, `,"type":`...)
+	dst = appendJSONString(dst, tree.Type)
+	if len(tree.Params) > 0 {
+		dst = append(dst, `,"params":`...)
+		dst = appendParamsJSON(dst, tree.Params)
+	}
+	if len(tree.Stack) > 0 {
+		dst = append(dst, `,"stack":`...)
+		dst = appendFramesJSON(dst, tree.Stack)
+	}
+	if len(tree.Causes) > 0 {
+		dst = append(dst, `,"causes":[`...)
+		for i := range tree.Causes {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendErrorTreeJSON(dst, &tree.Causes[i])
+		}
+		dst = append(dst, ']')
+	}
+	return append(dst, '}')
+}
+
+// appendJSONValue encodes common types directly and other values with encoding/json. Values that cannot
+// be encoded (e.g. channels, cycles or panicking MarshalJSON methods) are written as "!error: ..." strings.
+// Durations are written as strings like in Parameter.AppendJSON.
+func appendJSONValue(dst []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(dst, "null"...)
+	case string:
+		return appendJSONString(dst, v)
+	case bool:
+		return strconv.AppendBool(dst, v)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(dst, v, 10)
+	case float64:
+		return appendJSONFloat(dst, v)
+	case time.Duration:
+		dst = append(dst, '"')
+		dst = appendDuration(dst, v)
+		return append(dst, '"')
+	case time.Time:
+		dst = append(dst, '"')
+		dst = v.AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"')
+	}
+	return appendJSONMarshal(dst, value)
+}
+
+func appendJSONMarshal(dst []byte, value any) (result []byte) {
+	enc := encoders.Get()
+	defer encoders.Put(enc)
+	defer func() {
+		if r := recover(); r != nil {
+			result = appendJSONString(dst, fmt.Sprintf("!error: panic: %v", r))
 		}
-		_, _ = writer.Write(enc.buff.Bytes())
+	}()
+	enc.buff.Reset()
+	if err := enc.json.Encode(value); err != nil {
+		return appendJSONString(dst, "!error: "+err.Error())
 	}
+	return append(dst, enc.buff.Bytes()[:enc.buff.Len()-1]...)
 }
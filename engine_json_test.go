@@ -1,8 +1,15 @@
 package golog
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math"
+	"strings"
 	"testing"
+	"time"
 )
 
 func BenchmarkJSON(b *testing.B) {
@@ -16,6 +23,7 @@ func BenchmarkJSON(b *testing.B) {
 		b.Run("20", runWithModules(log, 20))
 	})
 	b.Run("WithDetails", runWithDetails(log))
+	b.Run("WithParams", runWithParams(log))
 	b.Run("UserMessage", func(b *testing.B) {
 		b.Run("10", runUserMessage(log, 10))
 		b.Run("25", runUserMessage(log, 25))
@@ -27,3 +35,89 @@ func BenchmarkJSON(b *testing.B) {
 		b.Run("1600", runUserMessage(log, 1600))
 	})
 }
+
+type panickingMarshaler struct{}
+
+func (panickingMarshaler) MarshalJSON() ([]byte, error) {
+	panic("broken")
+}
+
+type cyclic struct {
+	Next *cyclic `json:"next"`
+}
+
+func TestJSONEngineUnencodable(t *testing.T) {
+	loop := &cyclic{}
+	loop.Next = loop
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff))
+	log.Info().Details(make(chan int)).Send("chan")
+	log.Info().Details(loop).Send("cycle")
+	log.Info().Param("broken", panickingMarshaler{}).Send("panic")
+
+	lines := 0
+	dec := json.NewDecoder(buff)
+	for dec.More() {
+		var line struct {
+			Details any `json:"details"`
+			Params  []struct {
+				Value any `json:"value"`
+			} `json:"params"`
+		}
+		if err := dec.Decode(&line); err != nil {
+			t.Fatalf("invalid output: %v", err)
+		}
+		value := line.Details
+		if len(line.Params) == 1 {
+			value = line.Params[0].Value
+		}
+		if text, _ := value.(string); !strings.HasPrefix(text, "!error: ") {
+			t.Errorf("expected an error string, got %v", value)
+		}
+		lines++
+	}
+	if lines != 3 {
+		t.Errorf("expected 3 lines, got %d", lines)
+	}
+}
+
+func TestAppendJSONValue(t *testing.T) {
+	for _, value := range []any{nil, "a<b>&\u2028", true, -5, int8(-1), uint64(math.MaxUint64), 1e21, 0.000001,
+		time.Date(2020, 1, 2, 3, 4, 5, 6, time.FixedZone("X", 3600)), []byte("ab"),
+		map[string]any{"b": []int{1}, "a": nil}, struct{ A string }{"x"}} {
+		expected, _ := json.Marshal(value)
+		if got := appendJSONValue(nil, value); string(got) != string(expected) {
+			t.Errorf("%T: expected %s, got %s", value, expected, got)
+		}
+	}
+	if got := appendJSONValue(nil, 1500*time.Millisecond); string(got) != `"1.5s"` {
+		t.Errorf("unexpected duration: %s", got)
+	}
+}
+
+func BenchmarkJSONRecord(b *testing.B) {
+	log := New("test", JSONEngine(io.Discard)).With(Str("service", "api"), Int("pid", 1234))
+	err := fmt.Errorf("query: %w", errors.New("timeout"))
+	b.ReportAllocs()
+	for range b.N {
+		log.Warn().Str("query", "select * from users").Int("rows", 42).Dur("took", 1500*time.Millisecond).
+			Details(int64(5)).Err(err).Send("query failed")
+	}
+}
+
+func BenchmarkAppendJSONValue(b *testing.B) {
+	details := map[string]any{"id": 5, "tags": []string{"a", "b"}}
+	buff := make([]byte, 0, 1024)
+	b.Run("Fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buff = appendJSONValue(buff[:0], int64(5))
+		}
+	})
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buff = appendJSONValue(buff[:0], details)
+		}
+	})
+}
@@ -0,0 +1,305 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// LogfmtEngine writes messages as logfmt lines:
+//
+//	ts=2006-01-02T15:04:05Z level=info module=app/db msg="query done" request=abc rows=5
+//
+// Logger and context params are followed by message params. Details are flattened with dotted keys
+// (e.g. details.user.id=5), errors and stacks are written as error and stack.
+func LogfmtEngine(writers ...io.Writer) WriteEngine {
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+
+	writer := io.MultiWriter(writers...)
+
+	return func(log *Logger, data *MessageData) {
+		line := byteBuffers.Get()
+		defer byteBuffers.Put(line)
+		scratch := byteBuffers.Get()
+		defer byteBuffers.Put(scratch)
+
+		dst := append((*line)[:0], "ts="...)
+		*scratch = data.Time.AppendFormat((*scratch)[:0], log.DateTimeFormat(time.RFC3339Nano))
+		dst = appendLogfmtValue(dst, *scratch)
+		dst = append(dst, " level="...)
+		for _, c := range []byte(data.Level.String()) {
+			if 'A' <= c && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			dst = append(dst, c)
+		}
+		dst = append(dst, " module="...)
+		dst = appendLogfmtString(dst, log.ModulePath())
+		dst = append(dst, " msg="...)
+		dst = appendLogfmtValue(dst, data.Message)
+		if data.Caller.Defined() {
+			dst = append(dst, " caller="...)
+			*scratch = data.Caller.AppendShort((*scratch)[:0])
+			dst = appendLogfmtValue(dst, *scratch)
+		}
+		if trace, ok := data.Trace(); ok {
+			dst = append(dst, " trace_id="...)
+			dst = trace.AppendTraceID(dst)
+			dst = append(dst, " span_id="...)
+			dst = trace.AppendSpanID(dst)
+		}
+		if data.Duration > 0 {
+			dst = append(dst, " duration="...)
+			dst = appendDuration(dst, data.Duration)
+		}
+		for _, params := range [3][]Parameter{data.LoggerParams(), data.ContextParams(), data.Params} {
+			for _, param := range params {
+				dst = append(dst, ' ')
+				dst = appendLogfmtKey(dst, param.Name)
+				dst = append(dst, '=')
+				*scratch = param.AppendText((*scratch)[:0])
+				dst = appendLogfmtValue(dst, *scratch)
+			}
+		}
+		if data.Details != nil {
+			// details that cannot be encoded are written as details="!error: ..." like in JSONEngine
+			encoded := byteBuffers.Get()
+			*encoded = appendJSONValue((*encoded)[:0], data.Details)
+			key := append((*scratch)[:0], "details"...)
+			dst, _ = appendLogfmtJSON(dst, key, *encoded, 0)
+			byteBuffers.Put(encoded)
+		}
+		if data.Error != nil {
+			dst = append(dst, " error="...)
+			dst = appendLogfmtString(dst, data.Error.Error())
+		}
+		if data.StackIncluded {
+			dst = append(dst, " stack="...)
+			*scratch = data.Stack.AppendText((*scratch)[:0])
+			dst = appendLogfmtValue(dst, *scratch)
+		}
+		dst = append(dst, '\n')
+		*line = dst
+		_, _ = writer.Write(dst)
+	}
+}
+
+// appendLogfmtKey appends key with spaces, quotes, '=' and control characters replaced by '_'.
+func appendLogfmtKey(dst []byte, key string) []byte {
+	if key == "" {
+		return append(dst, '_')
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+func appendLogfmtString(dst []byte, value string) []byte {
+	return appendLogfmtValue(dst, unsafe.Slice(unsafe.StringData(value), len(value)))
+}
+
+// appendLogfmtValue appends value, quoted and escaped if it is empty or contains spaces, quotes, '=',
+// control characters or invalid UTF-8.
+func appendLogfmtValue(dst []byte, value []byte) []byte {
+	if !logfmtNeedsQuoting(value) {
+		return append(dst, value...)
+	}
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, `�`...)
+			} else {
+				dst = append(dst, value[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		case '\t':
+			dst = append(dst, '\\', 't')
+		default:
+			if c < ' ' || c == 0x7f {
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			} else {
+				dst = append(dst, c)
+			}
+		}
+		i++
+	}
+	return append(dst, '"')
+}
+
+func logfmtNeedsQuoting(value []byte) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for i := 0; i < len(value); {
+		c := value[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRune(value[i:])
+			if r == utf8.RuneError && size == 1 {
+				return true
+			}
+			i += size
+			continue
+		}
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+// appendLogfmtJSON flattens the JSON value starting at data[i] into " key=value" pairs. Objects add
+// their keys to key separated by dots; arrays are written as JSON. It returns the position after the value.
+func appendLogfmtJSON(dst []byte, key []byte, data []byte, i int) ([]byte, int) {
+	i = skipJSONSpace(data, i)
+	if i >= len(data) {
+		return dst, i
+	}
+	switch data[i] {
+	case '{':
+		i = skipJSONSpace(data, i+1)
+		if i < len(data) && data[i] == '}' {
+			dst = append(dst, ' ')
+			dst = append(dst, key...)
+			return append(dst, "={}"...), i + 1
+		}
+		for i < len(data) {
+			i = skipJSONSpace(data, i)
+			if data[i] != '"' {
+				return dst, len(data)
+			}
+			var name []byte
+			name, i = appendJSONUnquoted(append(key, '.'), data, i)
+			for n := len(key) + 1; n < len(name); n++ {
+				if c := name[n]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+					name[n] = '_'
+				}
+			}
+			i = skipJSONSpace(data, i)
+			if i >= len(data) || data[i] != ':' {
+				return dst, len(data)
+			}
+			dst, i = appendLogfmtJSON(dst, name, data, i+1)
+			i = skipJSONSpace(data, i)
+			if i >= len(data) {
+				return dst, i
+			}
+			if data[i] == '}' {
+				return dst, i + 1
+			}
+			i++
+		}
+		return dst, i
+	case '"':
+		dst = append(dst, ' ')
+		dst = append(dst, key...)
+		dst = append(dst, '=')
+		// the unquoted string is appended after key in its buffer, which is reused for nested keys only after this call
+		value, end := appendJSONUnquoted(key[len(key):], data, i)
+		return appendLogfmtValue(dst, value), end
+	default:
+		end := skipJSONValue(data, i)
+		dst = append(dst, ' ')
+		dst = append(dst, key...)
+		dst = append(dst, '=')
+		return appendLogfmtValue(dst, data[i:end]), end
+	}
+}
+
+func skipJSONSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// skipJSONValue returns the position after the array or scalar starting at data[i].
+func skipJSONValue(data []byte, i int) int {
+	depth := 0
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case ',', ' ', '\t', '\n', '\r':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// appendJSONUnquoted appends the JSON string starting at data[i] without quotes and escapes.
+// It returns the position after the closing quote.
+func appendJSONUnquoted(dst []byte, data []byte, i int) ([]byte, int) {
+	for i++; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			return dst, i + 1
+		case c == '\\' && i+1 < len(data):
+			i++
+			switch data[i] {
+			case 'n':
+				dst = append(dst, '\n')
+			case 'r':
+				dst = append(dst, '\r')
+			case 't':
+				dst = append(dst, '\t')
+			case 'b':
+				dst = append(dst, '\b')
+			case 'f':
+				dst = append(dst, '\f')
+			case 'u':
+				if i+4 < len(data) {
+					if r, err := strconv.ParseUint(string(data[i+1:i+5]), 16, 32); err == nil {
+						dst = utf8.AppendRune(dst, rune(r))
+					}
+					i += 4
+				}
+			default:
+				dst = append(dst, data[i])
+			}
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst, i
+}
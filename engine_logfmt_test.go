@@ -0,0 +1,90 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLogfmtEngine(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", LogfmtEngine(buff)).Module("db").Param("version", "1.0")
+	log.SetDateTimeFormat("2006-01-02 15:04")
+	ctx := WithParams(context.Background(), Str("request", "a b"))
+
+	log.Warn().Ctx(ctx).Str("query", `select "x"`).Int("rows", 5).Str("", "=").
+		Details(map[string]any{"user": map[string]any{"id": 5, "name": "jo\nhn"}, "tags": []string{"a", "b"}, "empty": ""}).
+		Err(errors.New("timeout")).Send("query failed")
+
+	line := buff.String()
+	expected := `level=warning module=app/db msg="query failed" version=1.0 request="a b" query="select \"x\"" rows=5 _="=" ` +
+		`details.empty="" details.tags="[\"a\",\"b\"]" details.user.id=5 details.user.name="jo\nhn" error=timeout` + "\n"
+	if !strings.HasPrefix(line, `ts="`) || !strings.HasSuffix(line, expected) {
+		t.Errorf("unexpected output: %s", line)
+	}
+}
+
+func TestLogfmtValue(t *testing.T) {
+	for value, expected := range map[string]string{
+		"plain":   "plain",
+		"":        `""`,
+		"a=b":     `"a=b"`,
+		`back\`:   `back\`,
+		"tab\tx":  `"tab\tx"`,
+		"bell\a":  `"bell\u0007"`,
+		"zażółć":  "zażółć",
+		"bad\xff": `"bad�"`,
+	} {
+		if got := string(appendLogfmtString(nil, value)); got != expected {
+			t.Errorf("%q: expected %s, got %s", value, expected, got)
+		}
+	}
+	if got := string(appendLogfmtKey(nil, "a b=\"c\"")); got != "a_b__c_" {
+		t.Errorf("unexpected key: %s", got)
+	}
+}
+
+func TestLogfmtDetails(t *testing.T) {
+	for details, expected := range map[string]string{
+		`"text"`:                    ` details=text`,
+		`{}`:                        ` details={}`,
+		`{"a b":{"c":[1,{"d":2}]}}`: ` details.a_b.c="[1,{\"d\":2}]"`,
+		`{"x":"é\"","y":null}`:      ` details.x="é\"" details.y=null`,
+	} {
+		got, _ := appendLogfmtJSON(nil, []byte("details"), []byte(details), 0)
+		if string(got) != expected {
+			t.Errorf("%s: expected %s, got %s", details, expected, got)
+		}
+	}
+}
+
+func TestLogfmtDetailsError(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", LogfmtEngine(buff))
+	log.Info().Details(make(chan int)).Send("broken")
+	if !strings.Contains(buff.String(), ` details="!error: json: unsupported type: chan int"`) {
+		t.Errorf("unexpected line: %s", buff)
+	}
+}
+
+func BenchmarkLogfmt(b *testing.B) {
+	log := New("test", LogfmtEngine(io.Discard))
+	b.Run("JustMessage", runJustMessage(log))
+	b.Run("Modules", func(b *testing.B) {
+		b.Run("1", runWithModules(log, 1))
+		b.Run("3", runWithModules(log, 3))
+		b.Run("5", runWithModules(log, 5))
+		b.Run("10", runWithModules(log, 10))
+		b.Run("20", runWithModules(log, 20))
+	})
+	b.Run("WithDetails", runWithDetails(log))
+	b.Run("WithParams", runWithParams(log))
+	b.Run("UserMessage", func(b *testing.B) {
+		b.Run("10", runUserMessage(log, 10))
+		b.Run("100", runUserMessage(log, 100))
+		b.Run("1600", runUserMessage(log, 1600))
+	})
+}
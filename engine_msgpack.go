@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+)
+
+// MsgpackEngine writes messages as MessagePack maps with the fields of JSONEngine. Each record is prefixed
+// with its length as a big-endian uint32; package logdecode reads them back. Timestamps use the timestamp
+// extension type (-1).
+func MsgpackEngine(writers ...io.Writer) WriteEngine {
+	return binaryEngine(msgpackFormat{}, writers)
+}
+
+type msgpackFormat struct{}
+
+// appendMsgpackHeader appends the fix variant if n fits in fixMax, otherwise the 8 (if code8 is set),
+// 16 or 32 bit variant.
+func appendMsgpackHeader(dst []byte, n int, fix byte, fixMax int, code8, code16, code32 byte) []byte {
+	switch {
+	case n <= fixMax:
+		return append(dst, fix|byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		return append(dst, code8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, code16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(dst, code32), uint32(n))
+}
+
+func (msgpackFormat) appendArray(dst []byte, n int) []byte {
+	return appendMsgpackHeader(dst, n, 0x90, 15, 0, 0xdc, 0xdd)
+}
+
+func (msgpackFormat) appendMap(dst []byte, n int) []byte {
+	return appendMsgpackHeader(dst, n, 0x80, 15, 0, 0xde, 0xdf)
+}
+
+func (f msgpackFormat) appendString(dst []byte, s string) []byte {
+	return append(f.appendStringHeader(dst, len(s)), s...)
+}
+
+func (msgpackFormat) appendStringHeader(dst []byte, n int) []byte {
+	return appendMsgpackHeader(dst, n, 0xa0, 31, 0xd9, 0xda, 0xdb)
+}
+
+func (msgpackFormat) appendBytes(dst []byte, b []byte) []byte {
+	return append(appendMsgpackHeader(dst, len(b), 0, -1, 0xc4, 0xc5, 0xc6), b...)
+}
+
+func (f msgpackFormat) appendInt(dst []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return f.appendUint(dst, uint64(v))
+	case v >= -32:
+		return append(dst, byte(v))
+	case v >= math.MinInt8:
+		return append(dst, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(v))
+}
+
+func (msgpackFormat) appendUint(dst []byte, v uint64) []byte {
+	switch {
+	case v <= math.MaxInt8:
+		return append(dst, byte(v))
+	case v <= math.MaxUint8:
+		return append(dst, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(dst, 0xcf), v)
+}
+
+func (msgpackFormat) appendFloat(dst []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(dst, 0xcb), math.Float64bits(v))
+}
+
+func (msgpackFormat) appendBool(dst []byte, v bool) []byte {
+	if v {
+		return append(dst, 0xc3)
+	}
+	return append(dst, 0xc2)
+}
+
+func (msgpackFormat) appendNil(dst []byte) []byte {
+	return append(dst, 0xc0)
+}
+
+// appendTime writes the 96-bit timestamp extension (ext 8 with nanoseconds and seconds).
+func (msgpackFormat) appendTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, 0xc7, 12, 0xff)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(dst, uint64(t.Unix()))
+}
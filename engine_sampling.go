@@ -0,0 +1,163 @@
+package golog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type SamplingOptions struct {
+	// Initial is the number of messages per window that are always written. Defaults to 100.
+	Initial int
+	// Thereafter writes every Thereafter-th message after Initial is reached. Zero drops all of them.
+	Thereafter int
+	// Window defaults to 1 second.
+	Window time.Duration
+}
+
+type sampleKey struct {
+	level  Level
+	path   string
+	format string
+}
+
+type sampleCounter struct {
+	count      int
+	suppressed int
+	log        *Logger
+}
+
+type sampleSummary struct {
+	key     sampleKey
+	counter *sampleCounter
+}
+
+type sampler struct {
+	mut         sync.Mutex
+	engine      WriteEngine
+	opts        SamplingOptions
+	windowStart time.Time
+	counters    map[sampleKey]*sampleCounter
+	timer       *time.Timer
+}
+
+const samplingSummaryFormat = "sampling suppressed %d messages"
+
+// Sample wraps engine, so messages with the same level, module path and Send format string are written
+// only Initial times per window and then once every Thereafter messages. Error and panic messages are never sampled.
+// At the end of every window with suppressed messages, a summary message is written for each of them.
+func Sample(engine WriteEngine, opts ...SamplingOptions) WriteEngine {
+	s := &sampler{
+		engine:   engine,
+		counters: map[sampleKey]*sampleCounter{},
+	}
+	if len(opts) > 0 {
+		s.opts = opts[0]
+	}
+	if s.opts.Initial <= 0 {
+		s.opts.Initial = 100
+	}
+	if s.opts.Window <= 0 {
+		s.opts.Window = time.Second
+	}
+	return managedWrapper(engine, s.write, EngineHooks{
+		Flush: s.flush,
+		Close: s.close,
+	})
+}
+
+func (s *sampler) write(log *Logger, data *MessageData) {
+	if data.Level.Order() <= LevelError.Order() {
+		s.engine(log, data)
+		return
+	}
+	key := sampleKey{level: data.Level, path: log.ModulePath(), format: data.Format}
+	now := time.Now()
+
+	s.mut.Lock()
+	var summaries []sampleSummary
+	if now.Sub(s.windowStart) >= s.opts.Window {
+		summaries = s.reset(now)
+	}
+	counter, ok := s.counters[key]
+	if !ok {
+		counter = &sampleCounter{}
+		s.counters[key] = counter
+	}
+	counter.count++
+	pass := counter.count <= s.opts.Initial ||
+		(s.opts.Thereafter > 0 && (counter.count-s.opts.Initial)%s.opts.Thereafter == 0)
+	if !pass {
+		counter.suppressed++
+		if counter.log == nil {
+			counter.log = log.snapshot()
+		}
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.windowStart.Add(s.opts.Window).Sub(now), s.tick)
+		}
+	}
+	s.mut.Unlock()
+
+	s.summarize(summaries)
+	if pass {
+		s.engine(log, data)
+	}
+}
+
+// reset starts a new window and returns summaries of the previous one. s.mut must be held.
+func (s *sampler) reset(now time.Time) []sampleSummary {
+	var summaries []sampleSummary
+	for key, counter := range s.counters {
+		if counter.suppressed > 0 {
+			summaries = append(summaries, sampleSummary{key: key, counter: counter})
+		}
+	}
+	clear(s.counters)
+	s.windowStart = now
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return summaries
+}
+
+func (s *sampler) tick() {
+	s.mut.Lock()
+	summaries := s.reset(time.Now())
+	s.mut.Unlock()
+	s.summarize(summaries)
+}
+
+func (s *sampler) summarize(summaries []sampleSummary) {
+	for _, summary := range summaries {
+		data := dataPool.Get()
+		data.Level = summary.key.level
+		data.Time = time.Now()
+		data.Format = samplingSummaryFormat
+		data.Message = fmt.Appendf(data.Message, samplingSummaryFormat, summary.counter.suppressed)
+		data.Params = append(data.Params,
+			Str("format", summary.key.format),
+			Int("suppressed", summary.counter.suppressed),
+			Dur("window", s.opts.Window),
+		)
+		resolveValues(summary.counter.log, data)
+		s.engine(summary.counter.log, data)
+		dataPool.Put(data)
+	}
+}
+
+func (s *sampler) flush(_ context.Context) error {
+	s.tick()
+	return nil
+}
+
+func (s *sampler) close(_ context.Context) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package golog
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSample(t *testing.T) {
+	var messages, summaries, errors int
+	var suppressed int64
+	engine := Sample(func(log *Logger, data *MessageData) {
+		switch {
+		case data.Format == samplingSummaryFormat:
+			summaries++
+			suppressed = data.Params[1].Int64()
+		case data.Level == LevelError:
+			errors++
+		default:
+			messages++
+		}
+	}, SamplingOptions{Initial: 5, Thereafter: 10, Window: time.Hour})
+	log := New("app", engine)
+	for i := range 100 {
+		log.Info().Send("message %d", i)
+		log.Error().Send("failure %d", i)
+	}
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if messages != 14 || errors != 100 {
+		t.Errorf("expected 14 messages and 100 errors, got %d and %d", messages, errors)
+	}
+	if summaries != 1 || suppressed != 86 {
+		t.Errorf("expected 1 summary with 86 suppressed messages, got %d with %v", summaries, suppressed)
+	}
+}
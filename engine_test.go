@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 )
 
 func runUserMessage(log *Logger, num int) func(b *testing.B) {
@@ -76,3 +77,21 @@ func runWithDetails(log *Logger) func(b *testing.B) {
 		})
 	}
 }
+
+func runWithParams(log *Logger) func(b *testing.B) {
+	log = log.Copy()
+	return func(b *testing.B) {
+		b.Run("typed", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				log.Info().Str("str", "value").Int("int", 5).Float("float", 3.14).Dur("dur", time.Second).Send("test")
+			}
+		})
+		b.Run("any", func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				log.Info().Param("str", "value").Param("int", 5).Param("float", 3.14).Param("dur", time.Second).Send("test")
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gookit/color"
+)
+
+// DefaultTextLayout mirrors the layout of ColorEngine.
+const DefaultTextLayout = "{level_color}{time} | {level} | [{modules} ][| {caller} ][| {ctx_params} ][| {params} ][| trace({trace}) ][| {duration} ]-> {message}{reset}"
+
+type layoutField uint8
+
+const (
+	fieldText layoutField = iota
+	fieldSection
+	fieldTime
+	fieldLevel
+	fieldLevelColor
+	fieldReset
+	fieldModules
+	fieldContextParams
+	fieldParams
+	fieldDuration
+	fieldCaller
+	fieldTrace
+	fieldMessage
+)
+
+var layoutFields = map[string]layoutField{
+	"time":        fieldTime,
+	"level":       fieldLevel,
+	"level_color": fieldLevelColor,
+	"reset":       fieldReset,
+	"modules":     fieldModules,
+	"ctx_params":  fieldContextParams,
+	"params":      fieldParams,
+	"duration":    fieldDuration,
+	"caller":      fieldCaller,
+	"trace":       fieldTrace,
+	"message":     fieldMessage,
+}
+
+type layoutPart struct {
+	field layoutField
+	// text is the literal text or the placeholder argument.
+	text  string
+	width int
+	parts []layoutPart
+}
+
+// Layout is a precompiled TextEngine template. Placeholders are written as {name}, {name:arg}, {name,width}
+// or {name,width:arg}:
+//
+//	{time:15:04:05}   time formatted with the argument (logger's date time format by default)
+//	{level}           level name
+//	{level_color}     level color code, {reset} resets it (both empty when terminal colors are disabled)
+//	{modules:/}       modules joined by the argument (space by default)
+//	{ctx_params}      logger and context params as name(value)
+//	{params}          message params as name(value)
+//	{duration}        message duration rounded to milliseconds
+//	{caller}          caller as package/file.go:line
+//	{trace}           first 8 hex characters of the trace id
+//	{message}         message
+//
+// A positive width pads the value on the left, a negative one on the right (e.g. {level,-7}).
+// Text between [ and ] is written only if at least one placeholder inside is not empty.
+// Sections can be nested. Escape literal braces, brackets and backslashes with a backslash (e.g. \[).
+type Layout struct {
+	source string
+	parts  []layoutPart
+}
+
+// ParseLayout compiles a layout for TextEngine.
+func ParseLayout(layout string) (*Layout, error) {
+	parts, _, err := parseLayoutParts(layout, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	return &Layout{source: layout, parts: parts}, nil
+}
+
+// MustParseLayout is like ParseLayout but panics if the layout cannot be parsed.
+func MustParseLayout(layout string) *Layout {
+	l, err := ParseLayout(layout)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+func (l *Layout) String() string {
+	return l.source
+}
+
+// AppendText appends the message formatted by the layout, without a trailing newline.
+func (l *Layout) AppendText(dst []byte, log *Logger, data *MessageData) []byte {
+	dst, _ = appendLayoutParts(dst, l.parts, log, data)
+	return dst
+}
+
+// parseLayoutParts parses layout from i until the end or, in a section, the closing ']'.
+// It returns the position of the closing ']'.
+func parseLayoutParts(layout string, i int, section bool) ([]layoutPart, int, error) {
+	var parts []layoutPart
+	var text []byte
+	flush := func() {
+		if len(text) > 0 {
+			parts = append(parts, layoutPart{field: fieldText, text: string(text)})
+			text = text[:0]
+		}
+	}
+	for i < len(layout) {
+		c := layout[i]
+		if c == '\\' && i+1 < len(layout) {
+			text = append(text, layout[i+1])
+			i += 2
+			continue
+		}
+		switch c {
+		case '{':
+			end := i + 1
+			for end < len(layout) && layout[end] != '}' {
+				end++
+			}
+			if end == len(layout) {
+				return nil, i, fmt.Errorf("layout: unclosed '{' at %d", i)
+			}
+			part, err := parseLayoutPlaceholder(layout[i+1 : end])
+			if err != nil {
+				return nil, i, fmt.Errorf("layout: %w at %d", err, i)
+			}
+			flush()
+			parts = append(parts, part)
+			i = end + 1
+		case '}':
+			return nil, i, fmt.Errorf("layout: unexpected '}' at %d", i)
+		case '[':
+			flush()
+			sub, end, err := parseLayoutParts(layout, i+1, true)
+			if err != nil {
+				return nil, end, err
+			}
+			if end == len(layout) {
+				return nil, i, fmt.Errorf("layout: unclosed '[' at %d", i)
+			}
+			parts = append(parts, layoutPart{field: fieldSection, parts: sub})
+			i = end + 1
+		case ']':
+			if !section {
+				return nil, i, fmt.Errorf("layout: unexpected ']' at %d", i)
+			}
+			flush()
+			return parts, i, nil
+		default:
+			text = append(text, c)
+			i++
+		}
+	}
+	flush()
+	return parts, i, nil
+}
+
+func parseLayoutPlaceholder(placeholder string) (layoutPart, error) {
+	name, arg, _ := cutByte(placeholder, ':')
+	name, width, hasWidth := cutByte(name, ',')
+	field, ok := layoutFields[name]
+	if !ok {
+		return layoutPart{}, fmt.Errorf("unknown placeholder %q", name)
+	}
+	part := layoutPart{field: field, text: arg}
+	if hasWidth {
+		w, err := strconv.Atoi(width)
+		if err != nil {
+			return layoutPart{}, fmt.Errorf("invalid width %q of %q", width, name)
+		}
+		part.width = w
+	}
+	return part, nil
+}
+
+func cutByte(s string, sep byte) (before, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == sep {
+			return s[:i], s[i+1:], true
+		}
+	}
+	return s, "", false
+}
+
+// appendLayoutParts appends parts and reports whether any placeholder was not empty.
+func appendLayoutParts(dst []byte, parts []layoutPart, log *Logger, data *MessageData) ([]byte, bool) {
+	written := false
+	for i := range parts {
+		part := &parts[i]
+		start := len(dst)
+		switch part.field {
+		case fieldText:
+			dst = append(dst, part.text...)
+			continue
+		case fieldSection:
+			var ok bool
+			dst, ok = appendLayoutParts(dst, part.parts, log, data)
+			if !ok {
+				dst = dst[:start]
+			}
+			written = written || ok
+			continue
+		case fieldTime:
+			format := part.text
+			if format == "" {
+				format = log.DateTimeFormat("02.01.2006 15:04:05")
+			}
+			dst = data.Time.AppendFormat(dst, format)
+		case fieldLevel:
+			dst = append(dst, data.Level.String()...)
+		case fieldLevelColor:
+			if !Config.DisableTerminalColors() {
+				dst = append(dst, data.Level.Color()...)
+			}
+		case fieldReset:
+			if !Config.DisableTerminalColors() {
+				dst = append(dst, color.ResetSet...)
+			}
+		case fieldModules:
+			sep := part.text
+			if sep == "" {
+				sep = " "
+			}
+			for n, module := range log.Modules() {
+				if n > 0 {
+					dst = append(dst, sep...)
+				}
+				dst = append(dst, module...)
+			}
+		case fieldContextParams:
+			dst = appendLayoutParams(dst, start, data.LoggerParams())
+			dst = appendLayoutParams(dst, start, data.ContextParams())
+		case fieldParams:
+			dst = appendLayoutParams(dst, start, data.Params)
+		case fieldDuration:
+			if data.Duration > 0 {
+				dst = appendDuration(dst, data.Duration.Round(time.Millisecond))
+			}
+		case fieldCaller:
+			if data.Caller.Defined() {
+				dst = data.Caller.AppendShort(dst)
+			}
+		case fieldTrace:
+			if trace, ok := data.Trace(); ok {
+				dst = trace.AppendTraceID(dst)[:start+8]
+			}
+		case fieldMessage:
+			dst = append(dst, data.Message...)
+		}
+		if len(dst) > start {
+			written = true
+		}
+		dst = padLayoutValue(dst, start, part.width)
+	}
+	return dst, written
+}
+
+// appendLayoutParams appends params separated by spaces from the previous ones written since start.
+func appendLayoutParams(dst []byte, start int, params []Parameter) []byte {
+	for _, param := range params {
+		if len(dst) > start {
+			dst = append(dst, ' ')
+		}
+		dst = append(dst, param.Name...)
+		dst = append(dst, '(')
+		dst = param.AppendText(dst)
+		dst = append(dst, ')')
+	}
+	return dst
+}
+
+// padLayoutValue pads dst[start:] with spaces to width runes, on the left if width is positive.
+func padLayoutValue(dst []byte, start, width int) []byte {
+	if width == 0 {
+		return dst
+	}
+	left := width > 0
+	if !left {
+		width = -width
+	}
+	pad := width - utf8.RuneCount(dst[start:])
+	if pad <= 0 {
+		return dst
+	}
+	end := len(dst)
+	for range pad {
+		dst = append(dst, ' ')
+	}
+	if left {
+		copy(dst[start+pad:], dst[start:end])
+		for i := start; i < start+pad; i++ {
+			dst[i] = ' '
+		}
+	}
+	return dst
+}
+
+// TextEngine writes messages formatted by layout (DefaultTextLayout if nil). Details, errors and stacks
+// are written below the line like in ColorEngine.
+func TextEngine(layout *Layout, writers ...io.Writer) WriteEngine {
+	if layout == nil {
+		layout = MustParseLayout(DefaultTextLayout)
+	}
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+
+	writer := io.MultiWriter(writers...)
+
+	return func(log *Logger, data *MessageData) {
+		buff := buffPool.Get()
+		defer buffPool.Put(buff)
+		buff.Write(layout.AppendText(buff.AvailableBuffer(), log, data))
+		if data.Details != nil {
+			buff.WriteByte('\n')
+			if !Config.MarshalDetails() {
+				_, _ = fmt.Fprint(buff, data.Details)
+			} else {
+				buff.Write(appendJSONValue(buff.AvailableBuffer(), data.Details))
+			}
+		}
+		buff.WriteByte('\n')
+		if data.Error != nil {
+			buff.Write(NewErrorTree(data.Error).AppendText(buff.AvailableBuffer()))
+		}
+		if data.StackIncluded {
+			buff.Write(data.Stack.AppendText(buff.AvailableBuffer()))
+		}
+		_, _ = writer.Write(buff.Bytes())
+	}
+}
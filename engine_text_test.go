@@ -0,0 +1,88 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestTextEngine(t *testing.T) {
+	Config.SetDisableTerminalColors(true)
+	defer Config.SetDisableTerminalColors(false)
+	layout := MustParseLayout(`{level,-7}|[ {modules:/} ]\{{message}\}[ ({duration})][ \[{ctx_params}[; {params}]\]]`)
+
+	for _, test := range []struct {
+		name     string
+		send     func(log *Logger)
+		expected string
+	}{
+		{"empty sections", func(log *Logger) { log.Info().Send("msg") }, "INFO   | app {msg}\n"},
+		{"all fields", func(log *Logger) {
+			ctx := WithParams(context.Background(), Str("request", "abc"))
+			log.With(Int("attempt", 2)).WithModule("db").Warn().Ctx(ctx).Str("query", "select").
+				Duration(1500 * time.Millisecond).Send("msg")
+		}, "WARNING| app/db {msg} (1.5s) [attempt(2) request(abc); query(select)]\n"},
+		{"nested section", func(log *Logger) { log.Error().Bool("ok", false).Send("msg") }, "ERROR  | app {msg} [; ok(false)]\n"},
+	} {
+		buff := new(bytes.Buffer)
+		test.send(New("app", TextEngine(layout, buff)))
+		if buff.String() != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, buff.String())
+		}
+	}
+
+	buff := new(bytes.Buffer)
+	New("app", TextEngine(MustParseLayout("{time:2006},{level,6}"), buff)).Info().Send("msg")
+	if expected := time.Now().Format("2006") + ",  INFO\n"; buff.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buff.String())
+	}
+}
+
+func TestTextEngineDefaultLayout(t *testing.T) {
+	Config.SetDisableTerminalColors(true)
+	defer Config.SetDisableTerminalColors(false)
+	Config.SetTraceExtractor(func(ctx context.Context) (TraceInfo, bool) {
+		return TraceInfo{TraceID: [16]byte{0xab, 0xcd, 0xef, 0x12, 0x34}, SpanID: [8]byte{1}}, true
+	})
+	defer Config.SetTraceExtractor(nil)
+
+	text, color := new(bytes.Buffer), new(bytes.Buffer)
+	log := New("app", TextEngine(nil, text), ColorEngine(color))
+	log.SetDateTimeFormat("15")
+	log.Info().Ctx(context.Background()).Details(make(chan int)).Send("traced")
+	if text.String() != color.String() {
+		t.Errorf("expected the output of ColorEngine %q, got %q", color.String(), text.String())
+	}
+	if !bytes.Contains(text.Bytes(), []byte("| trace(abcdef12) -> traced\n\"!error: ")) {
+		t.Errorf("unexpected output: %q", text.String())
+	}
+}
+
+func TestParseLayout(t *testing.T) {
+	for _, layout := range []string{"{unknown}", "{level", "[{level}", "{level}]", "{level,x}", "}"} {
+		if _, err := ParseLayout(layout); err == nil {
+			t.Errorf("%q: expected an error", layout)
+		}
+	}
+	if got := string(padLayoutValue([]byte("ab"), 0, 4)); got != "  ab" {
+		t.Errorf("unexpected padding: %q", got)
+	}
+}
+
+func BenchmarkText(b *testing.B) {
+	log := New("test", TextEngine(nil, io.Discard))
+	b.Run("JustMessage", runJustMessage(log))
+	b.Run("Modules", func(b *testing.B) {
+		b.Run("1", runWithModules(log, 1))
+		b.Run("5", runWithModules(log, 5))
+		b.Run("20", runWithModules(log, 20))
+	})
+	b.Run("WithParams", runWithParams(log))
+	b.Run("UserMessage", func(b *testing.B) {
+		b.Run("10", runUserMessage(log, 10))
+		b.Run("100", runUserMessage(log, 100))
+		b.Run("1600", runUserMessage(log, 1600))
+	})
+}
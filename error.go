@@ -0,0 +1,148 @@
+package golog
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// maxErrorDepth limits how deep error chains are resolved, guarding against cycles.
+const maxErrorDepth = 32
+
+// ErrorParams is implemented by errors carrying structured fields, which engines render alongside the error.
+type ErrorParams interface {
+	ErrorParams() []Parameter
+}
+
+// ErrorTree is a resolved error chain. Errors wrapped with Unwrap() error and joined with Unwrap() []error
+// become its causes.
+type ErrorTree struct {
+	Message string      `json:"message"`
+	Type    string      `json:"type"`
+	Params  []Parameter `json:"params,omitempty"`
+	// Stack is set for errors carrying their own stack trace (e.g. from github.com/pkg/errors).
+	Stack  []Frame     `json:"stack,omitempty"`
+	Causes []ErrorTree `json:"causes,omitempty"`
+}
+
+// NewErrorTree resolves the chain of err. It returns nil for nil errors.
+func NewErrorTree(err error) *ErrorTree {
+	if err == nil {
+		return nil
+	}
+	tree := newErrorTree(err, 0)
+	return &tree
+}
+
+func newErrorTree(err error, depth int) ErrorTree {
+	tree := ErrorTree{
+		Message: err.Error(),
+		Type:    reflect.TypeOf(err).String(),
+	}
+	if params, ok := err.(ErrorParams); ok {
+		tree.Params = params.ErrorParams()
+	}
+	if stack := errorStack(err); stack.Len() > 0 {
+		tree.Stack = stack.Frames()
+	}
+	if depth >= maxErrorDepth {
+		return tree
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			tree.Causes = []ErrorTree{newErrorTree(cause, depth+1)}
+		}
+	case interface{ Unwrap() []error }:
+		for _, cause := range e.Unwrap() {
+			if cause != nil {
+				tree.Causes = append(tree.Causes, newErrorTree(cause, depth+1))
+			}
+		}
+	case interface{ Cause() error }:
+		if cause := e.Cause(); cause != nil && cause != err {
+			tree.Causes = []ErrorTree{newErrorTree(cause, depth+1)}
+		}
+	}
+	return tree
+}
+
+// errorStack returns the stack trace carried by err. Errors with Callers() []uintptr (e.g. github.com/go-errors/errors)
+// and StackTrace() returning a slice of uintptr based frames (github.com/pkg/errors) are supported.
+func errorStack(err error) StackTrace {
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return NewStackTrace(e.Callers())
+	}
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return StackTrace{}
+	}
+	typ := method.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 || typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return StackTrace{}
+	}
+	frames := method.Call(nil)[0]
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return NewStackTrace(pcs)
+}
+
+// AppendText appends the tree with every cause on its own line, indented by its depth.
+func (t *ErrorTree) AppendText(dst []byte) []byte {
+	return t.appendText(dst, 0)
+}
+
+func (t *ErrorTree) appendText(dst []byte, depth int) []byte {
+	dst = appendIndent(dst, depth)
+	dst = append(dst, t.Type...)
+	dst = append(dst, ": "...)
+	// Continuation lines of multi-line messages (e.g. from errors.Join) are indented as well.
+	for i, line := range strings.Split(t.Message, "\n") {
+		if i > 0 {
+			dst = append(dst, '\n')
+			dst = appendIndent(dst, depth+1)
+		}
+		dst = append(dst, line...)
+	}
+	if len(t.Params) > 0 {
+		dst = append(dst, " |"...)
+		for _, param := range t.Params {
+			dst = append(dst, ' ')
+			dst = append(dst, param.Name...)
+			dst = append(dst, '(')
+			dst = param.AppendText(dst)
+			dst = append(dst, ')')
+		}
+	}
+	dst = append(dst, '\n')
+	for _, frame := range t.Stack {
+		dst = appendIndent(dst, depth+1)
+		dst = append(dst, frame.Function...)
+		dst = append(dst, ' ')
+		dst = append(dst, frame.File...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, '\n')
+	}
+	for i := range t.Causes {
+		dst = t.Causes[i].appendText(dst, depth+1)
+	}
+	return dst
+}
+
+func appendIndent(dst []byte, depth int) []byte {
+	for range depth {
+		dst = append(dst, "  "...)
+	}
+	return dst
+}
+
+// Err attaches err to the message. Unlike Throw, it does not capture a stack trace or replace the message.
+func (m Message) Err(err error) Message {
+	if m.data != nil {
+		m.data.Error = err
+	}
+	return m
+}
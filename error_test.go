@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type queryError struct {
+	query string
+}
+
+func (e queryError) Error() string {
+	return "query failed"
+}
+
+func (e queryError) ErrorParams() []Parameter {
+	return []Parameter{{Name: "query", Value: e.query}}
+}
+
+// pkgFrame and stackError mimic github.com/pkg/errors.
+type pkgFrame uintptr
+
+type stackError struct {
+	error
+	pcs []pkgFrame
+}
+
+func (e stackError) Unwrap() error {
+	return e.error
+}
+
+func (e stackError) StackTrace() []pkgFrame {
+	return e.pcs
+}
+
+func newStackError(msg string) stackError {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	err := stackError{error: errors.New(msg)}
+	for _, pc := range pcs[:n] {
+		err.pcs = append(err.pcs, pkgFrame(pc))
+	}
+	return err
+}
+
+func TestErrorTree(t *testing.T) {
+	stackErr := newStackError("timeout")
+	err := fmt.Errorf("load user: %w", errors.Join(queryError{query: "SELECT 1"}, stackErr))
+
+	tree := NewErrorTree(err)
+	if tree.Type != "*fmt.wrapError" || len(tree.Causes) != 1 {
+		t.Fatalf("unexpected root: %+v", tree)
+	}
+	joined := tree.Causes[0]
+	if joined.Type != "*errors.joinError" || len(joined.Causes) != 2 {
+		t.Fatalf("unexpected joined error: %+v", joined)
+	}
+	if query := joined.Causes[0]; len(query.Params) != 1 || query.Params[0].Value != "SELECT 1" {
+		t.Errorf("expected query params, got %+v", query.Params)
+	}
+	if stack := joined.Causes[1].Stack; len(stack) == 0 || !strings.HasSuffix(stack[0].Function, ".TestErrorTree") {
+		t.Errorf("expected stack starting at TestErrorTree, got %+v", stack)
+	}
+
+	text := string(tree.AppendText(nil))
+	for _, line := range []string{
+		"*fmt.wrapError: load user: query failed\n  timeout\n",
+		"  *errors.joinError: query failed\n    timeout\n",
+		"    golog.queryError: query failed | query(SELECT 1)\n",
+		"    golog.stackError: timeout\n      ",
+		"      *errors.errorString: timeout\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("expected %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestMessageErr(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff))
+	log.Warn().Err(fmt.Errorf("retry: %w", queryError{query: "SELECT 1"})).Send("cannot load")
+
+	var out struct {
+		Message string     `json:"message"`
+		Stack   []Frame    `json:"stack"`
+		Error   *ErrorTree `json:"error"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &out); err != nil {
+		t.Fatalf("cannot decode output: %v", err)
+	}
+	if out.Message != "cannot load" || out.Stack != nil {
+		t.Errorf("unexpected message: %+v", out)
+	}
+	if out.Error == nil || out.Error.Message != "retry: query failed" || len(out.Error.Causes) != 1 || out.Error.Causes[0].Params[0].Name != "query" {
+		t.Errorf("unexpected error: %+v", out.Error)
+	}
+}
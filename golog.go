@@ -3,6 +3,8 @@ package golog
 import (
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/BOOMfinity/go-utils/gpool"
 )
@@ -13,19 +15,38 @@ var dateTimeBuffer = gpool.New[[]byte](gpool.OnInit[[]byte](func(data *[]byte) {
 	*data = (*data)[:0:128]
 }))
 
+// byteBuffers holds buffers of engines appending messages to byte slices.
+var byteBuffers = gpool.New[[]byte](gpool.OnInit[[]byte](func(b *[]byte) {
+	*b = make([]byte, 0, Config.EngineBufferSize())
+}), gpool.OnPut[[]byte](func(b *[]byte) {
+	*b = (*b)[:0]
+}))
+
 func Wrap(eng ...WriteEngine) WriteEngine {
-	return func(log *Logger, data *MessageData) {
+	wrapped, hooks := wrapEngines(eng)
+	attachHooks(wrapped, hooks)
+	return wrapped
+}
+
+// wrapEngines is Wrap without registering the hooks of the result, so they can be kept by a Logger instead.
+func wrapEngines(eng []WriteEngine) (WriteEngine, []*managedEngine) {
+	wrapped := func(log *Logger, data *MessageData) {
 		for _, v := range eng {
 			v(log, data)
 		}
 	}
+	var hooks []*managedEngine
+	for _, v := range eng {
+		hooks = append(hooks, engineHooks(v)...)
+	}
+	return wrapped, hooks
 }
 
 type WriteEngine func(log *Logger, data *MessageData)
 
 type globalOptions struct {
-	mut                  sync.RWMutex
-	stackTraceBufferSize int
+	mut              sync.RWMutex
+	stackTraceFrames int
 	//loggerParametersSliceAllocation  int
 	//loggerModulesSliceAllocation     int
 	messageParametersSliceAllocation int
@@ -36,6 +57,12 @@ type globalOptions struct {
 	marshalDetails                   bool
 	detailsBufferSize                int
 	includeStackOnError              bool
+	shutdownTimeout                  time.Duration
+	traceExtractor                   TraceExtractor
+	moduleLevels                     []ModuleLevel
+	// levelVersion changes with module levels and the override level, so loggers can cache their levels.
+	levelVersion  atomic.Uint64
+	includeCaller bool
 }
 
 func (o *globalOptions) IncludeStackOnError() bool {
@@ -44,10 +71,18 @@ func (o *globalOptions) IncludeStackOnError() bool {
 	return o.includeStackOnError
 }
 
-func (o *globalOptions) StackTraceBufferSize() int {
+// StackTraceFrames returns the initial number of frames allocated for stack traces.
+func (o *globalOptions) StackTraceFrames() int {
 	o.mut.RLock()
 	defer o.mut.RUnlock()
-	return o.stackTraceBufferSize
+	return o.stackTraceFrames
+}
+
+// StackTraceBufferSize returns StackTraceFrames in bytes, a program counter (8 bytes) per frame.
+//
+// Deprecated: Use StackTraceFrames.
+func (o *globalOptions) StackTraceBufferSize() int {
+	return o.StackTraceFrames() * stackFrameSize
 }
 
 /*func (o *globalOptions) LoggerParametersSliceAllocation() int {
@@ -104,10 +139,40 @@ func (o *globalOptions) DetailsBufferSize() int {
 	return o.detailsBufferSize
 }
 
-func (o *globalOptions) SetStackTraceBufferSize(size int) {
+func (o *globalOptions) ShutdownTimeout() time.Duration {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	return o.shutdownTimeout
+}
+
+func (o *globalOptions) TraceExtractor() TraceExtractor {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	return o.traceExtractor
+}
+
+func (o *globalOptions) IncludeCaller() bool {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	return o.includeCaller
+}
+
+// SetStackTraceFrames sets the initial number of frames allocated for stack traces. Deeper stacks grow the buffer.
+func (o *globalOptions) SetStackTraceFrames(frames int) {
 	o.mut.Lock()
 	defer o.mut.Unlock()
-	o.stackTraceBufferSize = size
+	o.stackTraceFrames = frames
+}
+
+// stackFrameSize is the size of a captured frame, which is a program counter.
+const stackFrameSize = 8
+
+// SetStackTraceBufferSize sets the initial size in bytes of stack traces. Stacks are captured as program counters,
+// so the size is converted to frames of 8 bytes, e.g. 512 bytes are 64 frames.
+//
+// Deprecated: Use SetStackTraceFrames.
+func (o *globalOptions) SetStackTraceBufferSize(size int) {
+	o.SetStackTraceFrames(size / stackFrameSize)
 }
 
 /*func (o *globalOptions) SetLoggerParametersSliceAllocation(size int) {
@@ -144,6 +209,7 @@ func (o *globalOptions) SetOverrideMinimumMessageLevel(level Level) {
 	o.mut.Lock()
 	defer o.mut.Unlock()
 	o.overrideMinimumMessageLevel = level
+	o.levelVersion.Add(1)
 }
 
 func (o *globalOptions) SetDisableTerminalColors(disable bool) {
@@ -170,8 +236,26 @@ func (o *globalOptions) SetIncludeStackOnError(include bool) {
 	o.includeStackOnError = include
 }
 
+func (o *globalOptions) SetShutdownTimeout(timeout time.Duration) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.shutdownTimeout = timeout
+}
+
+func (o *globalOptions) SetTraceExtractor(extractor TraceExtractor) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.traceExtractor = extractor
+}
+
+func (o *globalOptions) SetIncludeCaller(include bool) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.includeCaller = include
+}
+
 var Config = globalOptions{
-	stackTraceBufferSize:             512,
+	stackTraceFrames:                 64,
 	messageParametersSliceAllocation: 25,
 	messageBufferSize:                512,
 	engineBufferSize:                 2048,
@@ -180,6 +264,8 @@ var Config = globalOptions{
 	marshalDetails:                   true,
 	detailsBufferSize:                1024,
 	includeStackOnError:              false,
+	includeCaller:                    false,
+	shutdownTimeout:                  5 * time.Second,
 }
 
 func init() {
@@ -192,4 +278,9 @@ func init() {
 			Config.SetOverrideMinimumMessageLevel(level)
 		}
 	}
+	if spec := os.Getenv("GOLOG_MODULE_LEVELS"); spec != "" {
+		if levels, err := ParseModuleLevels(spec); err == nil {
+			Config.SetModuleLevels(levels...)
+		}
+	}
 }
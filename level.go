@@ -0,0 +1,244 @@
+package golog
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Level of a message. Levels are compared by Level.Order; lower orders are more severe.
+type Level uint
+
+const (
+	LevelPanic Level = iota + 1
+	LevelError
+	LevelWarning
+	LevelInfo
+	LevelDebug
+	LevelTrace
+)
+
+// LevelDefinition describes a custom level.
+type LevelDefinition struct {
+	// Name is returned by Level.String and accepted by ParseLevel (case-insensitive), e.g. "NOTICE".
+	Name string
+	// Color is the terminal color code used by ColorEngine. Defaults to the color of Base.
+	Color string
+	// Base is the built-in level integrations (e.g. sentry) treat the level as.
+	Base Level
+	// Severity is the OpenTelemetry severity number (1-24). Zero uses the severity of Base.
+	Severity uint8
+	// Order places the level among the others. Built-in levels have orders 10 (PANIC) to 60 (TRACE),
+	// so e.g. 35 is between WARNING and INFO.
+	Order uint
+}
+
+var builtinLevels = map[Level]LevelDefinition{
+	LevelPanic:   {Name: "PANIC", Color: errorColorCode, Base: LevelPanic, Severity: 21, Order: 10},
+	LevelError:   {Name: "ERROR", Color: errorColorCode, Base: LevelError, Severity: 17, Order: 20},
+	LevelWarning: {Name: "WARNING", Color: warningColorCode, Base: LevelWarning, Severity: 13, Order: 30},
+	LevelInfo:    {Name: "INFO", Color: infoColorCode, Base: LevelInfo, Severity: 9, Order: 40},
+	LevelDebug:   {Name: "DEBUG", Color: debugColorCode, Base: LevelDebug, Severity: 5, Order: 50},
+	LevelTrace:   {Name: "TRACE", Color: traceColorCode, Base: LevelTrace, Severity: 1, Order: 60},
+}
+
+var levelAliases = map[string]Level{
+	"WARN":  LevelWarning,
+	"ERR":   LevelError,
+	"FATAL": LevelPanic,
+}
+
+var customLevels = struct {
+	mut    sync.RWMutex
+	levels map[Level]LevelDefinition
+}{levels: map[Level]LevelDefinition{}}
+
+// RegisterLevel adds a custom level. Its order decides which messages are written, e.g. a level with order 35
+// is written by loggers set to LevelInfo but not LevelWarning. It should be called before the level is used,
+// typically in init functions.
+func RegisterLevel(level Level, def LevelDefinition) error {
+	def.Name = strings.ToUpper(strings.TrimSpace(def.Name))
+	if level == 0 {
+		return errors.New("level must not be zero")
+	}
+	if def.Name == "" {
+		return errors.New("level name must not be empty")
+	}
+	if def.Order == 0 {
+		return fmt.Errorf("order of level %s must not be zero", def.Name)
+	}
+	if _, ok := builtinLevels[def.Base]; !ok {
+		return fmt.Errorf("base of level %s must be a built-in level", def.Name)
+	}
+	if def.Severity > 24 {
+		return fmt.Errorf("severity of level %s must be between 1 and 24", def.Name)
+	}
+	if def.Color == "" {
+		def.Color = builtinLevels[def.Base].Color
+	}
+	if _, ok := builtinLevels[level]; ok {
+		return fmt.Errorf("level %d is built-in", level)
+	}
+	customLevels.mut.Lock()
+	defer customLevels.mut.Unlock()
+	if _, ok := customLevels.levels[level]; ok {
+		return fmt.Errorf("level %d is already registered", level)
+	}
+	if _, ok := lookupLevel(def.Name); ok {
+		return fmt.Errorf("level name %s is already used", def.Name)
+	}
+	for _, levels := range [2]map[Level]LevelDefinition{builtinLevels, customLevels.levels} {
+		for _, other := range levels {
+			if other.Order == def.Order {
+				return fmt.Errorf("order %d of level %s is already used by %s", def.Order, def.Name, other.Name)
+			}
+		}
+	}
+	customLevels.levels[level] = def
+	return nil
+}
+
+func (l Level) definition() (LevelDefinition, bool) {
+	if def, ok := builtinLevels[l]; ok {
+		return def, true
+	}
+	customLevels.mut.RLock()
+	defer customLevels.mut.RUnlock()
+	def, ok := customLevels.levels[l]
+	return def, ok
+}
+
+// Defined reports whether the level is built-in or registered.
+func (l Level) Defined() bool {
+	_, ok := l.definition()
+	return ok
+}
+
+// String returns the level name. Undefined levels are rendered as LEVEL(n).
+func (l Level) String() string {
+	if def, ok := l.definition(); ok {
+		return def.Name
+	}
+	return "LEVEL(" + strconv.FormatUint(uint64(l), 10) + ")"
+}
+
+func (l Level) Color() string {
+	if def, ok := l.definition(); ok {
+		return def.Color
+	}
+	return l.Base().Color()
+}
+
+// Order returns the position of the level used to compare it with others. Undefined levels have the order
+// of their value multiplied by 10.
+func (l Level) Order() uint {
+	if l < LevelPanic || l > LevelTrace {
+		customLevels.mut.RLock()
+		def, ok := customLevels.levels[l]
+		customLevels.mut.RUnlock()
+		if ok {
+			return def.Order
+		}
+	}
+	// built-in orders are their values multiplied by 10 as well
+	return uint(l) * 10
+}
+
+// Base returns the built-in level the level maps to. Undefined levels map to the nearest less severe built-in level.
+func (l Level) Base() Level {
+	if def, ok := l.definition(); ok {
+		return def.Base
+	}
+	order := l.Order()
+	for level := LevelPanic; level < LevelTrace; level++ {
+		if order <= level.Order() {
+			return level
+		}
+	}
+	return LevelTrace
+}
+
+// Severity returns the OpenTelemetry severity number of the level.
+func (l Level) Severity() uint8 {
+	if def, ok := l.definition(); ok && def.Severity != 0 {
+		return def.Severity
+	}
+	return builtinLevels[l.Base()].Severity
+}
+
+// ParseLevel returns the level with the given name (case-insensitive). Aliases warn, err and fatal are accepted as well.
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	customLevels.mut.RLock()
+	level, ok := lookupLevel(name)
+	customLevels.mut.RUnlock()
+	if ok {
+		return level, nil
+	}
+	// undefined levels as rendered by String
+	if number, ok := strings.CutPrefix(name, "LEVEL("); ok {
+		if value, err := strconv.ParseUint(strings.TrimSuffix(number, ")"), 10, 0); err == nil && value != 0 {
+			return Level(value), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q", name)
+}
+
+// lookupLevel finds the level with the upper-case name. The caller must hold customLevels.mut.
+func lookupLevel(name string) (Level, bool) {
+	if level, ok := levelAliases[name]; ok {
+		return level, true
+	}
+	for _, levels := range [2]map[Level]LevelDefinition{builtinLevels, customLevels.levels} {
+		for level, def := range levels {
+			if def.Name == name {
+				return level, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func levelFromString(str string) Level {
+	level, _ := ParseLevel(str)
+	return level
+}
+
+// Levels returns built-in and registered levels from the most to the least severe.
+func Levels() []Level {
+	levels := make([]Level, 0, len(builtinLevels))
+	for level := range builtinLevels {
+		levels = append(levels, level)
+	}
+	customLevels.mut.RLock()
+	for level := range customLevels.levels {
+		levels = append(levels, level)
+	}
+	customLevels.mut.RUnlock()
+	slices.SortFunc(levels, func(a, b Level) int {
+		return cmp.Compare(a.Order(), b.Order())
+	})
+	return levels
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// Set implements flag.Value.
+func (l *Level) Set(name string) error {
+	return l.UnmarshalText([]byte(name))
+}
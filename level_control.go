@@ -0,0 +1,234 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type levelState struct {
+	Override     string            `json:"override"`
+	ModuleLevels map[string]string `json:"module_levels"`
+	Loggers      []loggerState     `json:"loggers"`
+}
+
+type loggerState struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+}
+
+// levelUpdate is the body of a PUT request. Empty level removes the override or module level.
+type levelUpdate struct {
+	Override     *string           `json:"override"`
+	ModuleLevels map[string]string `json:"module_levels"`
+	Loggers      map[string]string `json:"loggers"`
+}
+
+// LevelHandler returns an http.Handler that lists known loggers with their current levels (GET)
+// and changes levels at runtime (PUT). The PUT body is a JSON object with optional fields:
+//
+//	{
+//	  "override": "debug",                  // Config.SetOverrideMinimumMessageLevel, "" removes it
+//	  "module_levels": {"app/db": "trace"}, // Config.SetModuleLevel, "" removes the pattern
+//	  "loggers": {"app/http": "warning"}    // Logger.SetLevel of loggers with this module path
+//	}
+//
+// Loggers are those created with New. Changing the level of a logger matched by a module level, or while
+// the override is set, is rejected, because both take precedence over Logger.SetLevel.
+// Both methods respond with the current state.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var update levelUpdate
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := applyLevelUpdate(update); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(currentLevelState())
+	})
+}
+
+func parseLevelName(name string) (Level, error) {
+	if name == "" {
+		return 0, nil
+	}
+	return ParseLevel(name)
+}
+
+func applyLevelUpdate(update levelUpdate) error {
+	// validate everything first, so invalid requests do not change anything
+	var override Level
+	var err error
+	if update.Override != nil {
+		if override, err = parseLevelName(*update.Override); err != nil {
+			return err
+		}
+	}
+	modules := make(map[string]Level, len(update.ModuleLevels))
+	for pattern, name := range update.ModuleLevels {
+		if modules[pattern], err = parseLevelName(name); err != nil {
+			return err
+		}
+	}
+	loggers := make(map[string]Level, len(update.Loggers))
+	for path, name := range update.Loggers {
+		level, err := parseLevelName(name)
+		if err != nil {
+			return err
+		}
+		if level == 0 {
+			return fmt.Errorf("level of logger %q cannot be empty", path)
+		}
+		loggers[path] = level
+	}
+	if len(loggers) > 0 {
+		// the override applies before logger levels, so SetLevel would have no effect while it is set
+		effective := Config.OverrideMinimumMessageLevel()
+		if update.Override != nil {
+			effective = override
+		}
+		if effective != 0 {
+			return fmt.Errorf("logger levels cannot be changed while override %s is set", effective)
+		}
+		// Logger.Level prefers module levels, so SetLevel would have no effect on matching loggers
+		patterns := Config.ModuleLevels()
+		for pattern, level := range modules {
+			pattern = strings.Trim(pattern, "/")
+			patterns = slices.DeleteFunc(patterns, func(m ModuleLevel) bool { return m.Pattern == pattern })
+			if level != 0 {
+				patterns = append(patterns, ModuleLevel{Pattern: pattern, Level: level})
+			}
+		}
+		for path := range loggers {
+			for _, m := range patterns {
+				if m.matches(path) {
+					return fmt.Errorf("level of logger %q is set by module level %q, change module_levels instead", path, m.Pattern)
+				}
+			}
+		}
+	}
+
+	if update.Override != nil {
+		Config.SetOverrideMinimumMessageLevel(override)
+	}
+	for pattern, level := range modules {
+		Config.SetModuleLevel(pattern, level)
+	}
+	if len(loggers) > 0 {
+		for _, log := range Loggers() {
+			if level, ok := loggers[log.ModulePath()]; ok {
+				log.SetLevel(level)
+			}
+		}
+	}
+	return nil
+}
+
+func currentLevelState() levelState {
+	state := levelState{
+		ModuleLevels: map[string]string{},
+		Loggers:      []loggerState{},
+	}
+	if override := Config.OverrideMinimumMessageLevel(); override != 0 {
+		state.Override = override.String()
+	}
+	for _, level := range Config.ModuleLevels() {
+		state.ModuleLevels[level.Pattern] = level.Level.String()
+	}
+	for _, log := range Loggers() {
+		entry := loggerState{Module: log.ModulePath(), Level: log.Level().String()}
+		if !slices.Contains(state.Loggers, entry) {
+			state.Loggers = append(state.Loggers, entry)
+		}
+	}
+	slices.SortFunc(state.Loggers, func(a, b loggerState) int {
+		if c := strings.Compare(a.Module, b.Module); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Level, b.Level)
+	})
+	return state
+}
+
+// StepOverrideLevel moves Config override level by steps: positive steps make logs more verbose, negative less verbose.
+// Without override, it starts from LevelInfo. It returns the new override.
+func StepOverrideLevel(steps int) Level {
+	Config.mut.Lock()
+	defer Config.mut.Unlock()
+	current := Config.overrideMinimumMessageLevel
+	if current == 0 {
+		current = LevelInfo
+	}
+	levelOrder := Levels()
+	idx := slices.Index(levelOrder, current)
+	if idx == -1 {
+		idx = slices.Index(levelOrder, LevelInfo)
+	}
+	idx = min(max(idx+steps, 0), len(levelOrder)-1)
+	Config.overrideMinimumMessageLevel = levelOrder[idx]
+	Config.levelVersion.Add(1)
+	return levelOrder[idx]
+}
+
+// LevelSignals makes the process more verbose when it receives up and less verbose when it receives down,
+// using StepOverrideLevel. Without arguments it uses SIGUSR1 and SIGUSR2 (not supported on Windows).
+// Returned function stops listening.
+func LevelSignals(signals ...os.Signal) (stop func()) {
+	up, down := defaultLevelUpSignal, defaultLevelDownSignal
+	if len(signals) > 0 {
+		up = signals[0]
+	}
+	if len(signals) > 1 {
+		down = signals[1]
+	}
+	var listen []os.Signal
+	for _, sig := range []os.Signal{up, down} {
+		if sig != nil {
+			listen = append(listen, sig)
+		}
+	}
+	if len(listen) == 0 {
+		return func() {}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, listen...)
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if sig == up {
+					StepOverrideLevel(1)
+				} else {
+					StepOverrideLevel(-1)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package golog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer Config.SetOverrideMinimumMessageLevel(0)
+	defer Config.SetModuleLevels()
+
+	app := New("levels", ColorEngine(io.Discard))
+	db := app.Module("db")
+	handler := LevelHandler()
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"module_levels":{"levels/db":"trace"},"loggers":{"levels":"warning"}}`))
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.Code, res.Body)
+	}
+	if app.Level() != LevelWarning || db.Level() != LevelTrace {
+		t.Errorf("levels not changed: app=%s db=%s", app.Level(), db.Level())
+	}
+
+	var state levelState
+	if err := json.Unmarshal(res.Body.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.ModuleLevels["levels/db"] != "TRACE" {
+		t.Errorf("unexpected module levels: %v", state.ModuleLevels)
+	}
+	if !slices.Contains(state.Loggers, loggerState{Module: "levels", Level: "WARNING"}) ||
+		slices.ContainsFunc(state.Loggers, func(s loggerState) bool { return s.Module == "levels/db" }) {
+		t.Errorf("expected only loggers created with New: %v", state.Loggers)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"module_levels":{"levels":"debug"},"loggers":{"levels":"error"}}`))
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusBadRequest || app.Level() != LevelWarning {
+		t.Errorf("expected logger matched by a module level to be rejected, got %d (%s)", res.Code, app.Level())
+	}
+
+	for _, body := range []string{`{"override":"debug","loggers":{"levels":"error"}}`, `{"loggers":{"levels":"error"}}`} {
+		req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		res = httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected logger level to be rejected while overridden, got %d", body, res.Code)
+		}
+		Config.SetOverrideMinimumMessageLevel(LevelInfo)
+	}
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"override":"","loggers":{"levels":"error"}}`))
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusOK || app.Level() != LevelError {
+		t.Errorf("expected logger level with the override cleared, got %d (%s)", res.Code, app.Level())
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"override":"loud"}`))
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request, got %d", res.Code)
+	}
+}
+
+func TestStepOverrideLevel(t *testing.T) {
+	defer Config.SetOverrideMinimumMessageLevel(0)
+	log := New("step").SetLevel(LevelInfo)
+	if log.Enabled(LevelDebug) {
+		t.Fatal("debug should be disabled before stepping")
+	}
+	if level := StepOverrideLevel(1); level != LevelDebug {
+		t.Errorf("expected DEBUG, got %s", level)
+	}
+	if !log.Enabled(LevelDebug) || log.Level() != LevelDebug {
+		t.Errorf("existing logger did not follow the override: %s", log.Level())
+	}
+	if level := StepOverrideLevel(5); level != LevelTrace {
+		t.Errorf("expected TRACE, got %s", level)
+	}
+	if level := StepOverrideLevel(-10); level != LevelPanic {
+		t.Errorf("expected PANIC, got %s", level)
+	}
+	if log.Enabled(LevelError) {
+		t.Error("error should be disabled by the PANIC override")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package golog
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	defaultLevelUpSignal   os.Signal = syscall.SIGUSR1
+	defaultLevelDownSignal os.Signal = syscall.SIGUSR2
+)
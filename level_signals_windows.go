@@ -0,0 +1,10 @@
+//go:build windows
+
+package golog
+
+import "os"
+
+var (
+	defaultLevelUpSignal   os.Signal
+	defaultLevelDownSignal os.Signal
+)
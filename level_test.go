@@ -0,0 +1,94 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+)
+
+const levelNotice Level = 100
+
+func registerNotice(t *testing.T) {
+	t.Helper()
+	if err := RegisterLevel(levelNotice, LevelDefinition{Name: "notice", Base: LevelInfo, Severity: 10, Order: 35}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		customLevels.mut.Lock()
+		delete(customLevels.levels, levelNotice)
+		customLevels.mut.Unlock()
+	})
+}
+
+func TestCustomLevel(t *testing.T) {
+	registerNotice(t)
+	if levelNotice.String() != "NOTICE" || levelNotice.Color() != infoColorCode || levelNotice.Base() != LevelInfo || levelNotice.Severity() != 10 {
+		t.Errorf("unexpected definition: %s %q %s %d", levelNotice, levelNotice.Color(), levelNotice.Base(), levelNotice.Severity())
+	}
+	if err := RegisterLevel(101, LevelDefinition{Name: "Notice", Base: LevelInfo, Order: 36}); err == nil {
+		t.Errorf("expected duplicated name to be rejected")
+	}
+	if err := RegisterLevel(101, LevelDefinition{Name: "other", Base: LevelInfo, Order: 40}); err == nil {
+		t.Errorf("expected order of a built-in level to be rejected")
+	}
+	if err := RegisterLevel(101, LevelDefinition{Name: "other", Base: LevelInfo}); err == nil {
+		t.Errorf("expected level without order to be rejected")
+	}
+	if err := RegisterLevel(LevelInfo, LevelDefinition{Name: "other", Base: LevelInfo, Order: 45}); err == nil {
+		t.Errorf("expected built-in level to be rejected")
+	}
+
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff)).SetLevel(LevelWarning)
+	log.Log(levelNotice).Send("filtered")
+	log.SetLevel(LevelInfo)
+	log.Log(levelNotice).Send("notice")
+	if lines := decodeLines(t, buff); len(lines) != 1 || lines[0].Level != "NOTICE" {
+		t.Errorf("unexpected output: %+v", lines)
+	}
+
+	defer Config.SetOverrideMinimumMessageLevel(0)
+	if level := StepOverrideLevel(-1); level != levelNotice {
+		t.Errorf("expected NOTICE, got %s", level)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for name, expected := range map[string]Level{
+		"warn": LevelWarning, "Warning": LevelWarning, "err": LevelError, "fatal": LevelPanic, " trace ": LevelTrace, "LEVEL(42)": 42,
+	} {
+		if level, err := ParseLevel(name); err != nil || level != expected {
+			t.Errorf("%q: expected %s, got %s (%v)", name, expected, level, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Errorf("expected an error for unknown level")
+	}
+
+	var level Level
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "level", "")
+	if err := fs.Parse([]string{"-level", "debug"}); err != nil || level != LevelDebug {
+		t.Errorf("flag was not parsed: %s (%v)", level, err)
+	}
+	var out struct {
+		Level Level `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"warn"}`), &out); err != nil || out.Level != LevelWarning {
+		t.Errorf("level was not unmarshaled: %s (%v)", out.Level, err)
+	}
+	if encoded, _ := json.Marshal(out); string(encoded) != `{"level":"WARNING"}` {
+		t.Errorf("unexpected encoding: %s", encoded)
+	}
+}
+
+func TestUndefinedLevel(t *testing.T) {
+	level := Level(42)
+	if level.String() != "LEVEL(42)" || level.Base() != LevelTrace || level.Color() != traceColorCode {
+		t.Errorf("unexpected undefined level: %s %s", level, level.Base())
+	}
+	log := New("app", ColorEngine(io.Discard), JSONEngine(io.Discard)).SetLevel(LevelTrace)
+	log.Log(level).Send("no panic")
+}
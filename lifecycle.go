@@ -0,0 +1,174 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// EngineHooks are optional lifecycle callbacks of a WriteEngine.
+// Engines that buffer or hold resources (files, network clients) should register them using Managed.
+type EngineHooks struct {
+	// Flush writes out everything the engine has buffered so far.
+	Flush func(ctx context.Context) error
+	// Close releases resources held by the engine. It is called after Flush and only once.
+	Close func(ctx context.Context) error
+}
+
+type managedEngine struct {
+	hooks EngineHooks
+	mut   sync.Mutex
+	// closed is set under mut, but can be read without it.
+	closed atomic.Bool
+}
+
+func (m *managedEngine) flush(ctx context.Context) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.closed.Load() || m.hooks.Flush == nil {
+		return nil
+	}
+	return m.hooks.Flush(ctx)
+}
+
+func (m *managedEngine) isClosed() bool {
+	return m.closed.Load()
+}
+
+func (m *managedEngine) close(ctx context.Context) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.closed.Load() {
+		return nil
+	}
+	m.closed.Store(true)
+	var errs []error
+	if m.hooks.Flush != nil {
+		errs = append(errs, m.hooks.Flush(ctx))
+	}
+	if m.hooks.Close != nil {
+		errs = append(errs, m.hooks.Close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+var lifecycle = struct {
+	mut      sync.Mutex
+	engines  []*managedEngine
+	byEngine map[unsafe.Pointer][]*managedEngine
+}{
+	byEngine: map[unsafe.Pointer][]*managedEngine{},
+}
+
+// exit is replaced in tests.
+var exit = os.Exit
+
+// engineKey identifies engine by its closure, so lifecycle hooks can be found for engines passed to New, Wrap
+// and Managed. Loggers keep the hooks of their engines, so entries are only needed until the engines are closed.
+func engineKey(engine WriteEngine) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&engine))
+}
+
+func engineHooks(engine WriteEngine) []*managedEngine {
+	lifecycle.mut.Lock()
+	defer lifecycle.mut.Unlock()
+	return lifecycle.byEngine[engineKey(engine)]
+}
+
+func attachHooks(engine WriteEngine, hooks []*managedEngine) {
+	if len(hooks) == 0 {
+		return
+	}
+	lifecycle.mut.Lock()
+	lifecycle.byEngine[engineKey(engine)] = hooks
+	lifecycle.mut.Unlock()
+}
+
+// Managed registers lifecycle hooks for engine and returns a WriteEngine that should be used instead of it.
+// Hooks of all managed engines are run by Flush and Shutdown, and by Logger.Close for engines of that logger.
+func Managed(engine WriteEngine, hooks EngineHooks) WriteEngine {
+	managed := &managedEngine{hooks: hooks}
+	wrapped := func(log *Logger, data *MessageData) {
+		engine(log, data)
+	}
+	lifecycle.mut.Lock()
+	lifecycle.engines = append(lifecycle.engines, managed)
+	lifecycle.mut.Unlock()
+	attachHooks(wrapped, append(slices.Clone(engineHooks(engine)), managed))
+	return wrapped
+}
+
+// managedWrapper is Managed for outer, an engine writing to inner. The hooks of inner are attached to the result
+// before the new ones, so closing a logger closes the wrapper first and then the engine it writes to.
+func managedWrapper(inner, outer WriteEngine, hooks EngineHooks) WriteEngine {
+	wrapped := Managed(outer, hooks)
+	attachHooks(wrapped, append(slices.Clone(engineHooks(inner)), engineHooks(wrapped)...))
+	return wrapped
+}
+
+func registeredEngines() []*managedEngine {
+	lifecycle.mut.Lock()
+	defer lifecycle.mut.Unlock()
+	return slices.Clone(lifecycle.engines)
+}
+
+// Flush flushes all managed engines. Engines are flushed in reverse order of creation,
+// so wrappers are flushed before the engines they write to.
+func Flush(ctx context.Context) error {
+	engines := registeredEngines()
+	var errs []error
+	for _, engine := range slices.Backward(engines) {
+		errs = append(errs, engine.flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown flushes and closes all managed engines. Loggers must not be used after Shutdown.
+func Shutdown(ctx context.Context) error {
+	lifecycle.mut.Lock()
+	engines := lifecycle.engines
+	lifecycle.engines = nil
+	clear(lifecycle.byEngine)
+	lifecycle.mut.Unlock()
+	return closeEngines(ctx, engines)
+}
+
+// forgetClosedEngines removes closed engines, and hooks of engines that have nothing left to close.
+func forgetClosedEngines() {
+	lifecycle.mut.Lock()
+	defer lifecycle.mut.Unlock()
+	lifecycle.engines = slices.DeleteFunc(lifecycle.engines, (*managedEngine).isClosed)
+	for key, hooks := range lifecycle.byEngine {
+		if !slices.ContainsFunc(hooks, func(m *managedEngine) bool { return !m.isClosed() }) {
+			delete(lifecycle.byEngine, key)
+		}
+	}
+}
+
+func closeEngines(ctx context.Context, engines []*managedEngine) error {
+	var errs []error
+	for _, engine := range slices.Backward(engines) {
+		errs = append(errs, engine.close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes managed engines used by l.
+// Engines are shared with copies and modules of l, so they are closed for them as well.
+func (l *Logger) Close(ctx context.Context) error {
+	err := closeEngines(ctx, l.hooks)
+	forgetClosedEngines()
+	return err
+}
+
+// shutdownAndExit runs Shutdown limited by Config.ShutdownTimeout and exits the process with code.
+func shutdownAndExit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), Config.ShutdownTimeout())
+	_ = Shutdown(ctx)
+	cancel()
+	exit(code)
+}
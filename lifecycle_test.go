@@ -0,0 +1,113 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFatalFlushesAllEngines(t *testing.T) {
+	var exitCodes []int
+	exit = func(code int) {
+		exitCodes = append(exitCodes, code)
+	}
+	defer func() {
+		exit = os.Exit
+	}()
+
+	var written, flushed, closed int
+	sink := Managed(func(log *Logger, data *MessageData) {
+		written++
+	}, EngineHooks{
+		Flush: func(ctx context.Context) error {
+			flushed++
+			return nil
+		},
+		Close: func(ctx context.Context) error {
+			closed++
+			return nil
+		},
+	})
+	log := New("app", ColorEngine(io.Discard), sink)
+	log.Fatal(3).Send("fatal")
+
+	if written != 1 || flushed != 1 || closed != 1 {
+		t.Errorf("written=%d flushed=%d closed=%d, expected 1 each", written, flushed, closed)
+	}
+	if len(exitCodes) != 1 || exitCodes[0] != 3 {
+		t.Errorf("unexpected exit codes: %v", exitCodes)
+	}
+	if err := log.Close(context.Background()); err != nil || closed != 1 {
+		t.Errorf("engine closed twice or failed: %v", err)
+	}
+}
+
+func TestCloseForgetsEngines(t *testing.T) {
+	lifecycle.mut.Lock()
+	engines, hooks := len(lifecycle.engines), len(lifecycle.byEngine)
+	lifecycle.mut.Unlock()
+
+	var closed int
+	for range 10 {
+		sink := Managed(func(log *Logger, data *MessageData) {}, EngineHooks{
+			Close: func(ctx context.Context) error {
+				closed++
+				return nil
+			},
+		})
+		log := New("app", Wrap(sink, ColorEngine(io.Discard)))
+		if err := log.Module("db").Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if closed != 10 {
+		t.Errorf("expected 10 engines to be closed, got %d", closed)
+	}
+
+	lifecycle.mut.Lock()
+	defer lifecycle.mut.Unlock()
+	if len(lifecycle.engines) != engines || len(lifecycle.byEngine) != hooks {
+		t.Errorf("closed engines were not forgotten: %d engines, %d hooks", len(lifecycle.engines), len(lifecycle.byEngine))
+	}
+}
+
+func TestCloseWrappedEngines(t *testing.T) {
+	var closed []string
+	sink := func(name string) WriteEngine {
+		return Managed(func(log *Logger, data *MessageData) {}, EngineHooks{
+			Close: func(ctx context.Context) error {
+				closed = append(closed, name)
+				return nil
+			},
+		})
+	}
+	for name, wrap := range map[string]func(WriteEngine) WriteEngine{
+		"async":   func(engine WriteEngine) WriteEngine { return Async(engine).Engine() },
+		"dedup":   func(engine WriteEngine) WriteEngine { return Deduplicate(engine) },
+		"sampler": func(engine WriteEngine) WriteEngine { return Sample(engine) },
+	} {
+		closed = nil
+		if err := New("app", wrap(sink(name))).Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(closed) != 1 || closed[0] != name {
+			t.Errorf("%s: inner engine not closed: %v", name, closed)
+		}
+	}
+
+	buff := new(bytes.Buffer)
+	log := New("app", Async(Deduplicate(JSONEngine(buff), time.Hour)).Engine())
+	log.Info().Send("repeated")
+	log.Info().Send("repeated")
+	log.Info().Send("repeated")
+	if err := log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buff.String(), "repeated 2 times") {
+		t.Errorf("deduplicator not flushed: %s", buff)
+	}
+}
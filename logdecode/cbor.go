@@ -0,0 +1,224 @@
+package logdecode
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxDepth limits nesting of arrays and maps.
+const maxDepth = 256
+
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *cborDecoder) done() bool {
+	return d.pos == len(d.data)
+}
+
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errTruncated
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b, nil
+}
+
+// header reads the major type and its argument. Indefinite lengths are not supported.
+func (d *cborDecoder) header() (major byte, info byte, n uint64, err error) {
+	b, err := d.take(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		b, err = d.take(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return major, info, n, nil
+	}
+	return 0, 0, 0, fmt.Errorf("unsupported additional information %d at %d", info, d.pos-1)
+}
+
+func (d *cborDecoder) value() (any, error) {
+	major, info, n, err := d.header()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		return integer(n), nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("negative integer out of range at %d", d.pos)
+		}
+		return -1 - int64(n), nil
+	case 2:
+		b, err := d.take(n)
+		return append([]byte(nil), b...), err
+	case 3:
+		b, err := d.take(n)
+		return string(b), err
+	case 4:
+		if err = d.enter(n); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		items := make([]any, 0, n)
+		for range n {
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case 5:
+		if err = d.enter(n); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		fields := make(map[string]any, n)
+		for range n {
+			key, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			item, err := d.value()
+			if err != nil {
+				return nil, err
+			}
+			fields[mapKey(key)] = item
+		}
+		return fields, nil
+	case 6:
+		if err = d.enter(0); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		item, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		switch n {
+		case 1:
+			return epochTime(item)
+		case 1001:
+			return extendedTime(item)
+		}
+		return item, nil
+	}
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfFloat(uint16(n)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	}
+	return nil, fmt.Errorf("unsupported simple value %d at %d", n, d.pos)
+}
+
+// enter checks the nesting depth and that n items can fit in the remaining data.
+func (d *cborDecoder) enter(n uint64) error {
+	d.depth++
+	if d.depth > maxDepth {
+		return fmt.Errorf("nesting deeper than %d", maxDepth)
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return errTruncated
+	}
+	return nil
+}
+
+func (d *cborDecoder) leave() {
+	d.depth--
+}
+
+func epochTime(value any) (any, error) {
+	switch v := value.(type) {
+	case int64:
+		return time.Unix(v, 0), nil
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), nil
+	}
+	return nil, fmt.Errorf("unexpected epoch time %T", value)
+}
+
+// extendedTime decodes the map of tag 1001 (RFC 9581) with seconds (key 1) and an optional fraction
+// in milliseconds (key -3), microseconds (key -6) or nanoseconds (key -9).
+func extendedTime(value any) (any, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected extended time %T", value)
+	}
+	var sec int64
+	if err := as(fields["1"], &sec); err != nil {
+		return nil, fmt.Errorf("extended time seconds: %w", err)
+	}
+	var nsec int64
+	for key, scale := range map[string]int64{"-3": 1e6, "-6": 1e3, "-9": 1} {
+		if frac, ok := fields[key]; ok {
+			if err := as(frac, &nsec); err != nil || nsec < 0 || nsec >= 1e9/scale {
+				return nil, fmt.Errorf("invalid extended time fraction %v", frac)
+			}
+			nsec *= scale
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
+
+func halfFloat(bits uint16) float64 {
+	exp := int(bits>>10) & 0x1f
+	mant := float64(bits & 0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if bits&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// integer returns values fitting int64 as int64.
+func integer(n uint64) any {
+	if n <= math.MaxInt64 {
+		return int64(n)
+	}
+	return n
+}
+
+func mapKey(key any) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
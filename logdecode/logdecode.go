@@ -0,0 +1,291 @@
+// Package logdecode reads messages written by golog.CBOREngine and golog.MsgpackEngine.
+//
+//	r := logdecode.NewReader(file, logdecode.CBOR)
+//	for {
+//		record, err := r.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+package logdecode
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/BOOMfinity/golog/v2"
+)
+
+// MaxRecordSize limits the size of a single record read by Reader.
+const MaxRecordSize = 64 << 20
+
+type Format uint8
+
+const (
+	CBOR Format = iota + 1
+	Msgpack
+)
+
+func (f Format) String() string {
+	switch f {
+	case CBOR:
+		return "cbor"
+	case Msgpack:
+		return "msgpack"
+	}
+	return fmt.Sprintf("Format(%d)", uint8(f))
+}
+
+// Record is a decoded message. Param values and details are decoded into nil, bool, int64, uint64 (only above
+// math.MaxInt64), float64, string, []byte, time.Time, []any and map[string]any.
+type Record struct {
+	Time       time.Time
+	Level      golog.Level
+	LevelName  string
+	Module     []string
+	Context    []golog.Parameter
+	Params     []golog.Parameter
+	Stack      []golog.Frame
+	Duration   time.Duration
+	Details    any
+	Message    string
+	TraceID    string
+	SpanID     string
+	TraceFlags string
+	Caller     *golog.Frame
+	Error      *golog.ErrorTree
+}
+
+// Reader reads length-prefixed records from a stream.
+type Reader struct {
+	r      *bufio.Reader
+	format Format
+	buff   []byte
+}
+
+func NewReader(r io.Reader, format Format) *Reader {
+	return &Reader{r: bufio.NewReader(r), format: format}
+}
+
+// Next returns the next record. It returns io.EOF at the end of the stream
+// and io.ErrUnexpectedEOF if the stream ends inside a record.
+func (r *Reader) Next() (*Record, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > MaxRecordSize {
+		return nil, fmt.Errorf("logdecode: record of %d bytes exceeds MaxRecordSize", n)
+	}
+	if cap(r.buff) < int(n) {
+		r.buff = make([]byte, n)
+	}
+	r.buff = r.buff[:n]
+	if _, err := io.ReadFull(r.r, r.buff); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return Decode(r.buff, r.format)
+}
+
+// Decode decodes a single record without the length prefix. The record does not reference data.
+func Decode(data []byte, format Format) (*Record, error) {
+	var dec decoder
+	switch format {
+	case CBOR:
+		dec = &cborDecoder{data: data}
+	case Msgpack:
+		dec = &msgpackDecoder{data: data}
+	default:
+		return nil, fmt.Errorf("logdecode: unknown format %s", format)
+	}
+	value, err := dec.value()
+	if err != nil {
+		return nil, fmt.Errorf("logdecode: %s: %w", format, err)
+	}
+	if !dec.done() {
+		return nil, fmt.Errorf("logdecode: %s: trailing data after record", format)
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("logdecode: %s: record is %T, not a map", format, value)
+	}
+	return newRecord(fields)
+}
+
+// decoder decodes a single value of the format.
+type decoder interface {
+	value() (any, error)
+	done() bool
+}
+
+var errTruncated = errors.New("truncated data")
+
+func newRecord(fields map[string]any) (*Record, error) {
+	var record Record
+	var err error
+	field := func(name string, decode func(value any) error) {
+		if value, ok := fields[name]; ok && value != nil && err == nil {
+			if err = decode(value); err != nil {
+				err = fmt.Errorf("logdecode: field %s: %w", name, err)
+			}
+		}
+	}
+	field("timestamp", func(value any) error { return as(value, &record.Time) })
+	field("level", func(value any) (err error) {
+		var level int64
+		err = as(value, &level)
+		record.Level = golog.Level(level)
+		return
+	})
+	field("level_name", func(value any) error { return as(value, &record.LevelName) })
+	field("module", func(value any) (err error) {
+		record.Module, err = list(value, func(item any) (module string, err error) {
+			err = as(item, &module)
+			return
+		})
+		return
+	})
+	field("context", func(value any) (err error) {
+		record.Context, err = params(value)
+		return
+	})
+	field("params", func(value any) (err error) {
+		record.Params, err = params(value)
+		return
+	})
+	field("stack", func(value any) (err error) {
+		record.Stack, err = list(value, frame)
+		return
+	})
+	field("duration", func(value any) (err error) {
+		var ms int64
+		err = as(value, &ms)
+		record.Duration = time.Duration(ms) * time.Millisecond
+		return
+	})
+	field("details", func(value any) error {
+		record.Details = value
+		return nil
+	})
+	field("message", func(value any) error { return as(value, &record.Message) })
+	field("trace_id", func(value any) error { return as(value, &record.TraceID) })
+	field("span_id", func(value any) error { return as(value, &record.SpanID) })
+	field("trace_flags", func(value any) error { return as(value, &record.TraceFlags) })
+	field("caller", func(value any) error {
+		caller, err := frame(value)
+		record.Caller = &caller
+		return err
+	})
+	field("error", func(value any) error {
+		tree, err := errorTree(value)
+		record.Error = &tree
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func as[T any](value any, dst *T) error {
+	switch v := value.(type) {
+	case T:
+		*dst = v
+		return nil
+	case uint64:
+		// integers above math.MaxInt64 do not fit any field
+		return fmt.Errorf("integer %d out of range", v)
+	}
+	return fmt.Errorf("unexpected %T", value)
+}
+
+func list[T any](value any, decode func(item any) (T, error)) ([]T, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T, expected an array", value)
+	}
+	result := make([]T, 0, len(items))
+	for _, item := range items {
+		decoded, err := decode(item)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, decoded)
+	}
+	return result, nil
+}
+
+func object(value any) (map[string]any, error) {
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %T, expected a map", value)
+	}
+	return fields, nil
+}
+
+func params(value any) ([]golog.Parameter, error) {
+	return list(value, func(item any) (golog.Parameter, error) {
+		fields, err := object(item)
+		if err != nil {
+			return golog.Parameter{}, err
+		}
+		var name string
+		if err = as(fields["name"], &name); err != nil {
+			return golog.Parameter{}, fmt.Errorf("param name: %w", err)
+		}
+		return golog.Any(name, fields["value"]), nil
+	})
+}
+
+func frame(value any) (golog.Frame, error) {
+	fields, err := object(value)
+	if err != nil {
+		return golog.Frame{}, err
+	}
+	var f golog.Frame
+	var line int64
+	if err = errors.Join(as(fields["function"], &f.Function), as(fields["file"], &f.File), as(fields["line"], &line)); err != nil {
+		return golog.Frame{}, err
+	}
+	if line > math.MaxInt32 || line < 0 {
+		return golog.Frame{}, fmt.Errorf("invalid line %d", line)
+	}
+	f.Line = int(line)
+	return f, nil
+}
+
+func errorTree(value any) (tree golog.ErrorTree, err error) {
+	fields, err := object(value)
+	if err != nil {
+		return tree, err
+	}
+	if err = errors.Join(as(fields["message"], &tree.Message), as(fields["type"], &tree.Type)); err != nil {
+		return tree, err
+	}
+	if value, ok := fields["params"]; ok {
+		if tree.Params, err = params(value); err != nil {
+			return tree, err
+		}
+	}
+	if value, ok := fields["stack"]; ok {
+		if tree.Stack, err = list(value, frame); err != nil {
+			return tree, err
+		}
+	}
+	if value, ok := fields["causes"]; ok {
+		if tree.Causes, err = list(value, errorTree); err != nil {
+			return tree, err
+		}
+	}
+	return tree, nil
+}
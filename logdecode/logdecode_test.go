@@ -0,0 +1,147 @@
+package logdecode
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BOOMfinity/golog/v2"
+)
+
+func TestRoundTrip(t *testing.T) {
+	golog.Config.SetTraceExtractor(func(ctx context.Context) (golog.TraceInfo, bool) {
+		return golog.TraceInfo{TraceID: [16]byte{1}, SpanID: [8]byte{2}, Flags: 1}, true
+	})
+	defer golog.Config.SetTraceExtractor(nil)
+	at := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+
+	for _, format := range []Format{CBOR, Msgpack} {
+		buff := new(bytes.Buffer)
+		engine := golog.CBOREngine(buff)
+		if format == Msgpack {
+			engine = golog.MsgpackEngine(buff)
+		}
+		log := golog.New("app", engine).SetIncludeCaller(true).WithModule("db").With(golog.Str("version", "1.0"))
+		ctx := golog.WithParams(context.Background(), golog.Int("request", -7))
+		log.Warn().Ctx(ctx).Stack().
+			Str("query", "select").Uint64("rows", 1<<63).Float("ratio", 0.5).Bool("ok", false).
+			Dur("took", 1500*time.Millisecond).Time("at", at).Bytes("raw", []byte("x")).
+			Details(map[string]any{"ids": []int{1, 2}, "name": "joe"}).
+			Duration(2 * time.Second).
+			Err(fmt.Errorf("query: %w", errors.New("timeout"))).
+			Send("query failed")
+		log.Info().Send("second")
+
+		r := NewReader(buff, format)
+		record, err := r.Next()
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if record.Level != golog.LevelWarning || record.LevelName != "WARNING" || record.Message != "query failed" ||
+			!reflect.DeepEqual(record.Module, []string{"app", "db"}) || record.Duration != 2*time.Second ||
+			time.Since(record.Time) > time.Minute {
+			t.Errorf("%s: unexpected record: %+v", format, record)
+		}
+		if record.TraceID != "01000000000000000000000000000000" || record.SpanID != "0200000000000000" || record.TraceFlags != "01" {
+			t.Errorf("%s: unexpected trace: %s %s %s", format, record.TraceID, record.SpanID, record.TraceFlags)
+		}
+		if len(record.Context) != 2 || record.Context[0].Text() != "1.0" || record.Context[1].Int64() != -7 {
+			t.Errorf("%s: unexpected context: %v", format, record.Context)
+		}
+		expected := []any{"select", uint64(1 << 63), 0.5, false, "1.5s", at, "x"}
+		if len(record.Params) != len(expected) {
+			t.Fatalf("%s: unexpected params: %v", format, record.Params)
+		}
+		for i, param := range record.Params {
+			value := param.Any()
+			if at, ok := value.(time.Time); ok {
+				value = at.UTC()
+			}
+			if !reflect.DeepEqual(value, expected[i]) {
+				t.Errorf("%s: param %s: expected %#v, got %#v", format, param.Name, expected[i], value)
+			}
+		}
+		details := map[string]any{"ids": []any{int64(1), int64(2)}, "name": "joe"}
+		if !reflect.DeepEqual(record.Details, details) {
+			t.Errorf("%s: unexpected details: %#v", format, record.Details)
+		}
+		if record.Caller == nil || !strings.HasSuffix(record.Caller.File, "logdecode_test.go") || len(record.Stack) == 0 {
+			t.Errorf("%s: unexpected caller and stack: %v %v", format, record.Caller, record.Stack)
+		}
+		if record.Error == nil || record.Error.Message != "query: timeout" || len(record.Error.Causes) != 1 ||
+			record.Error.Causes[0].Message != "timeout" {
+			t.Errorf("%s: unexpected error: %+v", format, record.Error)
+		}
+
+		if record, err = r.Next(); err != nil || record.Message != "second" || record.Error != nil || record.Stack != nil {
+			t.Errorf("%s: unexpected second record: %+v (%v)", format, record, err)
+		}
+		if _, err = r.Next(); err != io.EOF {
+			t.Errorf("%s: expected EOF, got %v", format, err)
+		}
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	log := golog.New("app")
+	for _, at := range []time.Time{
+		time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC),
+		time.Date(1900, 1, 1, 0, 0, 0, 1, time.UTC),
+		time.Date(2300, 12, 31, 23, 59, 59, 999999999, time.UTC),
+	} {
+		for _, format := range []Format{CBOR, Msgpack} {
+			buff := new(bytes.Buffer)
+			engine := golog.CBOREngine(buff)
+			if format == Msgpack {
+				engine = golog.MsgpackEngine(buff)
+			}
+			engine(log, &golog.MessageData{Time: at, Level: golog.LevelInfo, Params: []golog.Parameter{golog.Time("at", at)}})
+			record, err := NewReader(buff, format).Next()
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			if !record.Time.Equal(at) || len(record.Params) != 1 || !record.Params[0].Time().Equal(at) {
+				t.Errorf("%s: expected %s, got %s and %v", format, at, record.Time, record.Params)
+			}
+		}
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	buff := new(bytes.Buffer)
+	golog.New("app", golog.CBOREngine(buff)).Info().Send("message")
+	data := buff.Bytes()
+
+	if _, err := NewReader(bytes.NewReader(data[:len(data)-1]), CBOR).Next(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := Decode(data[4:len(data)-1], CBOR); err == nil {
+		t.Errorf("expected an error for truncated record")
+	}
+	if _, err := Decode(data[4:], Msgpack); err == nil {
+		t.Errorf("expected an error for wrong format")
+	}
+	huge := binary.BigEndian.AppendUint32(nil, MaxRecordSize+1)
+	if _, err := NewReader(bytes.NewReader(huge), CBOR).Next(); err == nil {
+		t.Errorf("expected an error for huge record")
+	}
+	nested := append(bytes.Repeat([]byte{0x81}, maxDepth+1), 0xf6)
+	if _, err := Decode(nested, CBOR); err == nil {
+		t.Errorf("expected an error for deep nesting")
+	}
+}
+
+func TestHalfFloat(t *testing.T) {
+	for bits, expected := range map[uint16]float64{0x3c00: 1, 0xc000: -2, 0x3555: 0.333251953125, 0x0001: 5.960464477539063e-08} {
+		if got := halfFloat(bits); got != expected {
+			t.Errorf("%04x: expected %v, got %v", bits, expected, got)
+		}
+	}
+}
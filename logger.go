@@ -1,17 +1,25 @@
 package golog
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 type Logger struct {
 	mut            sync.RWMutex
 	level          Level
 	modules        []string
+	path           string
 	params         []Parameter
 	engine         WriteEngine
+	hooks          []*managedEngine
 	dateTimeFormat string
+	ctx            context.Context
+	includeCaller  bool
+	// levelCache holds the level set by Config for path.
+	levelCache atomic.Pointer[levelCache]
 }
 
 func (l *Logger) SetDateTimeFormat(format string) {
@@ -33,7 +41,10 @@ func (l *Logger) DateTimeFormat(fallback ...string) string {
 	return format
 }
 
+// Modules returns modules of the logger. The slice must not be modified.
 func (l *Logger) Modules() []string {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
 	return l.modules
 }
 
@@ -51,27 +62,48 @@ func (l *Logger) ClearParams() *Logger {
 	return l
 }
 
+// Param adds a parameter to the logger itself, so it is visible to everyone sharing it. Use With to create a child logger instead.
 func (l *Logger) Param(name string, value any) *Logger {
 	l.mut.Lock()
+	// a new slice is created, so slices returned by Params stay unchanged
 	params := make([]Parameter, len(l.params), len(l.params)+1)
 	copy(params, l.params)
-	params = append(params, Parameter{
+	l.params = append(params, Parameter{
 		Name:  name,
 		Value: value,
 	})
-	l.params = params
 	l.mut.Unlock()
 	return l
 }
 
+// Level returns the minimum level of messages that are sent. Config.OverrideMinimumMessageLevel has
+// the highest priority, then the module level matching the logger modules, then the level set by SetLevel.
 func (l *Logger) Level() Level {
-	if overrideLevel := Config.OverrideMinimumMessageLevel(); overrideLevel != 0 {
-		return overrideLevel
+	cache := l.levelCache.Load()
+	if version := Config.levelVersion.Load(); cache == nil || cache.version != version {
+		// path is never modified after the logger is shared, so it can be read without the lock
+		level, ok := Config.configuredLevel(l.path)
+		cache = &levelCache{version: version, level: level, ok: ok}
+		l.levelCache.Store(cache)
+	}
+	if cache.ok {
+		return cache.level
 	}
 	l.mut.RLock()
-	lvl := l.level
-	l.mut.RUnlock()
-	return lvl
+	defer l.mut.RUnlock()
+	return l.level
+}
+
+// Enabled reports whether messages of level are written by the logger.
+func (l *Logger) Enabled(level Level) bool {
+	return level.Order() <= l.Level().Order()
+}
+
+// ModulePath returns modules of the logger joined with "/", as matched by Config module levels.
+func (l *Logger) ModulePath() string {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+	return l.path
 }
 
 func (l *Logger) SetLevel(lvl Level) *Logger {
@@ -81,32 +113,74 @@ func (l *Logger) SetLevel(lvl Level) *Logger {
 	return l
 }
 
+// Module is an alias of WithModule.
 func (l *Logger) Module(name string, scope ...string) *Logger {
-	cpy := l.doCopy()
-	n := name
+	return l.WithModule(name, scope...)
+}
+
+// WithModule returns a child logger with the module appended. The receiver is not modified.
+func (l *Logger) WithModule(name string, scope ...string) *Logger {
+	cpy := l.clone(1, 0)
 	if len(scope) > 0 {
-		n = fmt.Sprintf("%s@%s", n, scope[0])
+		name = fmt.Sprintf("%s@%s", name, scope[0])
 	}
-	cpy.modules = append(cpy.modules, n)
+	cpy.modules = append(cpy.modules, name)
+	cpy.path = modulePath(cpy.modules)
+	return cpy
+}
+
+// With returns a child logger with params appended, sharing the engine of the receiver, which is not modified.
+// Unlike Param, it is safe to use on loggers shared between goroutines, e.g. to add request params in a handler.
+func (l *Logger) With(params ...Parameter) *Logger {
+	cpy := l.clone(0, len(params))
+	cpy.params = append(cpy.params, params...)
 	return cpy
 }
 
 func (l *Logger) doCopy(scope ...string) *Logger {
+	cpy := l.clone(0, 0)
+	if len(scope) > 0 && len(cpy.modules) > 0 {
+		last := len(cpy.modules) - 1
+		cpy.modules[last] = fmt.Sprintf("%s@%s", cpy.modules[last], scope[0])
+	}
+	return cpy
+}
+
+// clone copies l with room for extra modules and params. The copy is not shared yet, so it can be modified.
+func (l *Logger) clone(modules, params int) *Logger {
 	l.mut.RLock()
-	params := make([]Parameter, len(l.params))
-	copy(params, l.params)
-	modules := make([]string, len(l.modules))
-	copy(modules, l.modules)
-	if len(scope) > 0 && len(modules) > 0 {
-		modules[len(l.modules)-1] = fmt.Sprintf("%s@%s", modules[len(l.modules)-1], scope[0])
+	defer l.mut.RUnlock()
+	return &Logger{
+		level:          l.level,
+		modules:        append(make([]string, 0, len(l.modules)+modules), l.modules...),
+		path:           l.path,
+		params:         append(make([]Parameter, 0, len(l.params)+params), l.params...),
+		engine:         l.engine,
+		hooks:          l.hooks,
+		dateTimeFormat: l.dateTimeFormat,
+		ctx:            l.ctx,
+		includeCaller:  l.includeCaller,
 	}
+}
+
+// snapshot returns a shallow copy of l. Modules and params are never modified in place,
+// so it can be used after l has changed.
+func (l *Logger) snapshot() *Logger {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
 	cpy := &Logger{
-		level:   l.level,
-		modules: modules,
-		params:  params,
-		engine:  l.engine,
+		level:          l.level,
+		modules:        l.modules,
+		path:           l.path,
+		params:         l.params,
+		engine:         l.engine,
+		hooks:          l.hooks,
+		dateTimeFormat: l.dateTimeFormat,
+		ctx:            l.ctx,
+		includeCaller:  l.includeCaller,
 	}
-	l.mut.RUnlock()
+	// the path is the same, so is the level set by Config
+	cpy.levelCache.Store(l.levelCache.Load())
 	return cpy
 }
 
@@ -130,6 +204,12 @@ func (l *Logger) Warn() Message {
 	return newMessage(l, LevelWarning)
 }
 
+// Log starts a message of any level, including levels registered with RegisterLevel.
+// Unlike Error and Fatal, it never captures stacks on its own nor exits.
+func (l *Logger) Log(level Level) Message {
+	return newMessage(l, level)
+}
+
 func (l *Logger) Error() Message {
 	return newErrorMessage(l)
 }
@@ -149,6 +229,8 @@ func newLogger(name string, eng ...WriteEngine) *Logger {
 	log := new(Logger)
 	log.level = LevelInfo
 	log.modules = append(log.modules, name)
-	log.engine = Wrap(eng...)
+	log.path = modulePath(log.modules)
+	log.engine, log.hooks = wrapEngines(eng)
+	registerLogger(log)
 	return log
 }
@@ -18,10 +18,11 @@ func (l *Logger) Recover(params ...RecoverParams) {
 			if p.ExitCode == 0 {
 				p.ExitCode = 1
 			}
-			msg = l.Fatal(p.ExitCode)
+			msg = newErrorMessage(l, p.ExitCode)
 		} else {
-			msg = l.Error()
+			msg = newErrorMessage(l)
 		}
+		msg = msg.CallerSkip(1)
 		if err, ok := v.(error); ok {
 			msg.Throw(err)
 		} else {
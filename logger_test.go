@@ -0,0 +1,70 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	buff := new(bytes.Buffer)
+	parent := New("app", JSONEngine(buff)).Param("version", "1.0")
+	parent.SetDateTimeFormat("15:04")
+
+	child := parent.With(Str("request", "abc"), Int("attempt", 2))
+	module := child.WithModule("db", "pool")
+	if len(parent.Params()) != 1 || len(child.Params()) != 3 || len(module.Params()) != 3 {
+		t.Errorf("unexpected params: parent %v, child %v, module %v", parent.Params(), child.Params(), module.Params())
+	}
+	if len(child.Modules()) != 1 || len(module.Modules()) != 2 || module.Modules()[1] != "db@pool" || module.ModulePath() != "app/db" {
+		t.Errorf("unexpected modules: %v (%s)", module.Modules(), module.ModulePath())
+	}
+	if child.Copy().DateTimeFormat() != "15:04" || module.DateTimeFormat() != "15:04" {
+		t.Errorf("date time format was not copied")
+	}
+
+	module.Info().Send("query")
+	parent.Info().Send("other")
+	lines := decodeLines(t, buff)
+	if len(lines) != 2 || len(lines[0].Context) != 3 || lines[0].Context[1].Value != "abc" || len(lines[1].Context) != 1 {
+		t.Errorf("unexpected output: %+v", lines)
+	}
+}
+
+func TestLoggerConcurrency(t *testing.T) {
+	parent := New("app", func(log *Logger, data *MessageData) {
+		_ = log.Modules()
+		_ = log.Params()
+		_ = log.DateTimeFormat()
+	})
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				child := parent.With(Int("worker", i), Int("job", j))
+				child.WithModule(fmt.Sprintf("job-%d", j)).Info().Str("state", "done").Send("job finished")
+				if len(child.Params()) != 2 {
+					t.Errorf("child params leaked: %v", child.Params())
+					return
+				}
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 100 {
+			parent.SetDateTimeFormat("15:04:05")
+			parent.SetLevel(LevelDebug)
+			parent.Copy().Param("copy", i).Info().Send("copy")
+			_ = Loggers()
+		}
+	}()
+	wg.Wait()
+	if len(parent.Params()) != 0 {
+		t.Errorf("parent was modified: %v", parent.Params())
+	}
+}
@@ -0,0 +1,203 @@
+// Package logtest helps testing code that logs with golog.
+//
+//	rec := logtest.New()
+//	log := golog.New("app", rec.Engine())
+//	run(log)
+//	rec.AssertMessage(t, golog.LevelError, "cannot connect").AssertParam(t, "host", "db")
+package logtest
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BOOMfinity/golog/v2"
+)
+
+// Entry is a captured message. Entries returned by failed lookups are empty.
+type Entry struct {
+	// Data is a deep copy of the message data.
+	Data          *golog.MessageData
+	Modules       []string
+	LoggerParams  []golog.Parameter
+	ContextParams []golog.Parameter
+}
+
+func (e Entry) Level() golog.Level {
+	if e.Data == nil {
+		return 0
+	}
+	return e.Data.Level
+}
+
+func (e Entry) Message() string {
+	if e.Data == nil {
+		return ""
+	}
+	return string(e.Data.Message)
+}
+
+// Param looks the parameter up in message params, then in logger and context params.
+func (e Entry) Param(name string) (golog.Parameter, bool) {
+	if e.Data == nil {
+		return golog.Parameter{}, false
+	}
+	for _, params := range [][]golog.Parameter{e.Data.Params, e.LoggerParams, e.ContextParams} {
+		for _, param := range params {
+			if param.Name == name {
+				return param, true
+			}
+		}
+	}
+	return golog.Parameter{}, false
+}
+
+// ParamEquals reports whether the parameter exists and has value. Values are compared by their kind and text,
+// so golog.Int("n", 5) equals 5, int64(5) and uint8(5).
+func (e Entry) ParamEquals(name string, value any) bool {
+	param, ok := e.Param(name)
+	if !ok {
+		return false
+	}
+	expected := golog.Any(name, value)
+	if param.Kind() == golog.KindAny || expected.Kind() == golog.KindAny {
+		return reflect.DeepEqual(param.Any(), value)
+	}
+	if normalizeKind(param.Kind()) != normalizeKind(expected.Kind()) {
+		return false
+	}
+	return bytes.Equal(param.AppendText(nil), expected.AppendText(nil))
+}
+
+// normalizeKind treats signed and unsigned integers as well as strings and bytes as the same kinds.
+func normalizeKind(kind golog.ParamKind) golog.ParamKind {
+	switch kind {
+	case golog.KindUint:
+		return golog.KindInt
+	case golog.KindBytes:
+		return golog.KindString
+	}
+	return kind
+}
+
+// AssertParam fails the test if the parameter does not exist or has a different value.
+func (e Entry) AssertParam(t testing.TB, name string, value any) Entry {
+	t.Helper()
+	if !e.ParamEquals(name, value) {
+		param, ok := e.Param(name)
+		if !ok {
+			t.Errorf("message %q has no param %q", e.Message(), name)
+		} else {
+			t.Errorf("message %q: expected param %q to be %v, got %s", e.Message(), name, value, param.AppendText(nil))
+		}
+	}
+	return e
+}
+
+// Recorder is an engine storing copies of messages. It is safe for concurrent use.
+type Recorder struct {
+	mut     sync.Mutex
+	entries []Entry
+}
+
+func New() *Recorder {
+	return &Recorder{}
+}
+
+// Engine returns the engine capturing messages into the recorder.
+func (r *Recorder) Engine() golog.WriteEngine {
+	return func(log *golog.Logger, data *golog.MessageData) {
+		entry := Entry{
+			Data:          data.Clone(),
+			Modules:       append([]string(nil), log.Modules()...),
+			LoggerParams:  append([]golog.Parameter(nil), data.LoggerParams()...),
+			ContextParams: append([]golog.Parameter(nil), data.ContextParams()...),
+		}
+		r.mut.Lock()
+		r.entries = append(r.entries, entry)
+		r.mut.Unlock()
+	}
+}
+
+// Entries returns captured messages in the order they were written.
+func (r *Recorder) Entries() []Entry {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Reset removes captured messages.
+func (r *Recorder) Reset() {
+	r.mut.Lock()
+	r.entries = nil
+	r.mut.Unlock()
+}
+
+// Find returns the first message of level containing substring. Level 0 matches every level.
+func (r *Recorder) Find(level golog.Level, substring string) (Entry, bool) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	for _, entry := range r.entries {
+		if (level == 0 || entry.Level() == level) && strings.Contains(entry.Message(), substring) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// HasMessage reports whether a message of level containing substring was captured. Level 0 matches every level.
+func (r *Recorder) HasMessage(level golog.Level, substring string) bool {
+	_, ok := r.Find(level, substring)
+	return ok
+}
+
+// Count returns the number of captured messages of level. Level 0 counts every message.
+func (r *Recorder) Count(level golog.Level) int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	count := 0
+	for _, entry := range r.entries {
+		if level == 0 || entry.Level() == level {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertMessage fails the test if no message of level containing substring was captured.
+// Captured messages are listed in the failure.
+func (r *Recorder) AssertMessage(t testing.TB, level golog.Level, substring string) Entry {
+	t.Helper()
+	entry, ok := r.Find(level, substring)
+	if !ok {
+		t.Errorf("no %s message containing %q, captured:\n%s", levelName(level), substring, r.dump())
+	}
+	return entry
+}
+
+// AssertCount fails the test if the number of captured messages of level differs from expected.
+func (r *Recorder) AssertCount(t testing.TB, level golog.Level, expected int) {
+	t.Helper()
+	if count := r.Count(level); count != expected {
+		t.Errorf("expected %d %s messages, got %d, captured:\n%s", expected, levelName(level), count, r.dump())
+	}
+}
+
+func levelName(level golog.Level) string {
+	if level == 0 {
+		return "any"
+	}
+	return level.String()
+}
+
+func (r *Recorder) dump() string {
+	var buff []byte
+	for _, entry := range r.Entries() {
+		buff = append(buff, '\t')
+		buff = appendEntry(buff, entry.Data, entry.Modules, entry.LoggerParams, entry.ContextParams)
+		buff = append(buff, '\n')
+	}
+	return string(buff)
+}
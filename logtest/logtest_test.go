@@ -0,0 +1,87 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BOOMfinity/golog/v2"
+)
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	errors []string
+	logs   []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Log(args ...any) {
+	f.logs = append(f.logs, fmt.Sprint(args...))
+}
+
+func TestRecorder(t *testing.T) {
+	rec := New()
+	log := golog.New("app", rec.Engine()).Param("version", "1.0").Module("db")
+	ctx := golog.WithParams(context.Background(), golog.Str("request", "abc"))
+
+	buff := []byte("reused")
+	log.Info().Ctx(ctx).Int("rows", 5).Bytes("query", buff).Send("query %s", "done")
+	copy(buff, "REUSED")
+	log.Error().Err(errors.New("timeout")).Send("cannot connect")
+	log.Debug().Send("filtered")
+
+	if rec.Count(0) != 2 || rec.Count(golog.LevelInfo) != 1 || !rec.HasMessage(golog.LevelError, "connect") || rec.HasMessage(golog.LevelInfo, "connect") {
+		t.Fatalf("unexpected entries: %s", rec.dump())
+	}
+	entry, _ := rec.Find(golog.LevelInfo, "query")
+	if entry.Message() != "query done" || len(entry.Modules) != 2 || entry.Modules[1] != "db" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	for name, value := range map[string]any{"rows": 5, "query": "reused", "version": "1.0", "request": "abc"} {
+		if !entry.ParamEquals(name, value) {
+			t.Errorf("expected %s to equal %v", name, value)
+		}
+	}
+	if entry.ParamEquals("rows", "5") || entry.ParamEquals("missing", nil) {
+		t.Errorf("unexpected param match")
+	}
+
+	fake := &fakeTB{}
+	rec.AssertMessage(fake, golog.LevelInfo, "query").AssertParam(fake, "rows", uint(5))
+	rec.AssertCount(fake, golog.LevelError, 1)
+	if len(fake.errors) != 0 {
+		t.Errorf("unexpected failures: %v", fake.errors)
+	}
+	rec.AssertMessage(fake, golog.LevelWarning, "query")
+	rec.AssertCount(fake, 0, 3)
+	entry.AssertParam(fake, "rows", 6)
+	if len(fake.errors) != 3 || !strings.Contains(fake.errors[0], "INFO app db | version=1.0 request=abc rows=5 query=reused -> query done") {
+		t.Errorf("unexpected failures: %v", fake.errors)
+	}
+
+	rec.Reset()
+	if len(rec.Entries()) != 0 {
+		t.Errorf("entries were not removed")
+	}
+}
+
+func TestEngine(t *testing.T) {
+	fake := &fakeTB{}
+	log := golog.New("app", Engine(fake))
+	log.Warn().Str("key", "value").Send("warning")
+	if len(fake.logs) != 1 || fake.logs[0] != "WARNING app | key=value -> warning" {
+		t.Errorf("unexpected logs: %q", fake.logs)
+	}
+
+	log, rec := NewLogger(t, "app")
+	log.Trace().Send("captured and logged")
+	rec.AssertCount(t, golog.LevelTrace, 1)
+}
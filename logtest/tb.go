@@ -0,0 +1,51 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/BOOMfinity/golog/v2"
+)
+
+// Engine writes messages through t.Log, so they are shown with the test that produced them
+// (and only when it fails or runs with -v). Messages must not be written after the test completes.
+func Engine(t testing.TB) golog.WriteEngine {
+	return func(log *golog.Logger, data *golog.MessageData) {
+		t.Helper()
+		t.Log(string(appendEntry(nil, data, log.Modules(), data.LoggerParams(), data.ContextParams())))
+	}
+}
+
+// NewLogger creates a logger writing through t.Log and capturing messages into the returned recorder.
+func NewLogger(t testing.TB, name string) (*golog.Logger, *Recorder) {
+	rec := New()
+	return golog.New(name, Engine(t), rec.Engine()).SetLevel(golog.LevelTrace), rec
+}
+
+// appendEntry formats a message as "LEVEL modules | params -> message".
+func appendEntry(dst []byte, data *golog.MessageData, modules []string, params ...[]golog.Parameter) []byte {
+	dst = append(dst, data.Level.String()...)
+	for _, module := range modules {
+		dst = append(dst, ' ')
+		dst = append(dst, module...)
+	}
+	first := true
+	for _, list := range append(params, data.Params) {
+		for _, param := range list {
+			if first {
+				dst = append(dst, " |"...)
+				first = false
+			}
+			dst = append(dst, ' ')
+			dst = append(dst, param.Name...)
+			dst = append(dst, '=')
+			dst = param.AppendText(dst)
+		}
+	}
+	dst = append(dst, " -> "...)
+	dst = append(dst, data.Message...)
+	if data.Error != nil {
+		dst = append(dst, " | error: "...)
+		dst = append(dst, data.Error.Error()...)
+	}
+	return dst
+}
@@ -1,9 +1,8 @@
 package golog
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"runtime"
 	"strings"
 	"time"
 
@@ -11,153 +10,281 @@ import (
 	"github.com/BOOMfinity/go-utils/inlineif"
 )
 
-type Level uint
-
-const (
-	LevelPanic Level = iota + 1
-	LevelError
-	LevelWarning
-	LevelInfo
-	LevelDebug
-	LevelTrace
-)
-
-func (l Level) String() string {
-	switch l {
-	case LevelPanic:
-		return "PANIC"
-	case LevelError:
-		return "ERROR"
-	case LevelWarning:
-		return "WARNING"
-	case LevelInfo:
-		return "INFO"
-	case LevelDebug:
-		return "DEBUG"
-	case LevelTrace:
-		return "TRACE"
-	default:
-		panic("undefined logging level")
-	}
-}
-
-func (l Level) Color() string {
-	switch l {
-	case LevelPanic:
-		return errorColorCode
-	case LevelError:
-		return errorColorCode
-	case LevelWarning:
-		return warningColorCode
-	case LevelInfo:
-		return infoColorCode
-	case LevelDebug:
-		return debugColorCode
-	case LevelTrace:
-		return traceColorCode
-	default:
-		panic("undefined logging level")
-	}
-}
-
-func levelFromString(str string) Level {
-	switch strings.ToUpper(str) {
-	case "PANIC":
-		return LevelPanic
-	case "ERROR":
-		return LevelError
-	case "WARNING":
-		return LevelWarning
-	case "INFO":
-		return LevelInfo
-	case "DEBUG":
-		return LevelDebug
-	case "TRACE":
-		return LevelTrace
-	default:
-		return 0
-	}
-}
-
 type MessageData struct {
 	Details       any           `json:"details,omitempty"`
 	Level         Level         `json:"level,omitempty"`
-	Stack         []byte        `json:"stack,omitempty"`
+	Stack         StackTrace    `json:"-"`
 	StackIncluded bool          `json:"-"`
 	Error         error         `json:"-"`
 	ExitCode      int           `json:"exit_code,omitempty"`
 	Duration      time.Duration `json:"duration,omitempty"`
 	Message       []byte        `json:"message,omitempty"`
 	Params        []Parameter   `json:"params,omitempty"`
+	Time          time.Time     `json:"time"`
+	// Context is set by Message.Ctx or by loggers from FromContext.
+	Context context.Context `json:"-"`
+	// Format is the format string passed to Send, before arguments are applied.
+	Format string `json:"-"`
+	// Caller is captured when enabled by Config.SetIncludeCaller or Logger.SetIncludeCaller.
+	Caller Caller `json:"caller,omitempty"`
+
+	callerSkip int
+	// loggerParams and contextParams are params of the logger and Context, resolved before engines run.
+	loggerParams  []Parameter
+	contextParams []Parameter
+	// resolvedParams holds copies of loggerParams and contextParams if any of them had to be resolved.
+	resolvedParams []Parameter
 }
 
+// LoggerParams returns params of the logger that sent the message, resolved like Params.
+func (d *MessageData) LoggerParams() []Parameter {
+	return d.loggerParams
+}
+
+// ContextParams returns params of Context (see WithParams), resolved like Params.
+func (d *MessageData) ContextParams() []Parameter {
+	return d.contextParams
+}
+
+// Clone returns a copy of d, which stays valid after the engine returns. Details, Error and Context are
+// shared with d, as they are not reused by golog; everything else is copied.
+func (d *MessageData) Clone() *MessageData {
+	cpy := new(MessageData)
+	copyMessageData(cpy, d)
+	return cpy
+}
+
+// copyMessageData makes dst a copy of src like Clone, reusing buffers of dst.
+func copyMessageData(dst, src *MessageData) {
+	dst.Details = src.Details
+	dst.Level = src.Level
+	dst.Stack.pcs = append(dst.Stack.pcs[:0], src.Stack.pcs...)
+	dst.StackIncluded = src.StackIncluded
+	dst.Error = src.Error
+	dst.ExitCode = src.ExitCode
+	dst.Duration = src.Duration
+	dst.Message = append(dst.Message[:0], src.Message...)
+	dst.Params = append(dst.Params[:0], src.Params...)
+	for i := range dst.Params {
+		if dst.Params[i].kind == KindBytes {
+			dst.Params[i].str = strings.Clone(dst.Params[i].str)
+		}
+	}
+	dst.loggerParams, dst.contextParams = src.loggerParams, src.contextParams
+	if len(src.resolvedParams) > 0 {
+		// resolved params are stored in src, which is reused after the engine returns
+		dst.resolvedParams = append(dst.resolvedParams[:0], src.resolvedParams...)
+		n := len(src.loggerParams)
+		dst.loggerParams, dst.contextParams = dst.resolvedParams[:n:n], dst.resolvedParams[n:]
+	}
+	dst.Time = src.Time
+	dst.Context = src.Context
+	dst.Format = src.Format
+	dst.Caller = src.Caller
+}
+
+// Message is built by Logger methods and written by Send. Messages of disabled levels have no data,
+// so building them is free.
 type Message struct {
 	data   *MessageData
 	parent *Logger
+	// exitCode of a disabled Fatal message, which still has to exit.
+	exitCode int
 }
 
+// Stack captures the stack trace of the caller.
 func (m Message) Stack() Message {
-	l := runtime.Stack(m.data.Stack[:cap(m.data.Stack)], false)
-	m.data.Stack = m.data.Stack[:l]
-	m.data.StackIncluded = true
+	if m.data != nil {
+		m.stack(1)
+	}
 	return m
 }
 
+// stack captures the stack trace, skipping skip frames above the caller of stack.
+func (m Message) stack(skip int) {
+	m.data.Stack.capture(skip + 1)
+	m.data.StackIncluded = true
+}
+
 func (m Message) Throw(err error) {
+	if m.data == nil {
+		m.discard()
+		return
+	}
 	m.data.Error = err
-	m.Stack().Send(err.Error())
+	m.data.callerSkip++
+	if !m.data.StackIncluded {
+		m.stack(m.data.callerSkip)
+	}
+	// error messages may contain '%', so they must not be used as format strings
+	m.sendText(err.Error())
 }
 
 func (m Message) Details(v any) Message {
-	m.data.Details = v
+	if m.data != nil {
+		m.data.Details = v
+	}
 	return m
 }
 
 func (m Message) Param(name string, value any) Message {
-	m.data.Params = append(m.data.Params, Parameter{
+	return m.param(Parameter{
 		Name:  name,
 		Value: value,
 	})
+}
+
+func (m Message) param(param Parameter) Message {
+	if m.data != nil {
+		m.data.Params = append(m.data.Params, param)
+	}
+	return m
+}
+
+// Params appends parameters built with typed constructors, e.g. golog.Err("cause", err).
+func (m Message) Params(params ...Parameter) Message {
+	if m.data != nil {
+		m.data.Params = append(m.data.Params, params...)
+	}
 	return m
 }
 
+func (m Message) Str(name, value string) Message {
+	return m.param(Str(name, value))
+}
+
+func (m Message) Int(name string, value int) Message {
+	return m.param(Int(name, value))
+}
+
+func (m Message) Int64(name string, value int64) Message {
+	return m.param(Int64(name, value))
+}
+
+func (m Message) Uint(name string, value uint) Message {
+	return m.param(Uint(name, value))
+}
+
+func (m Message) Uint64(name string, value uint64) Message {
+	return m.param(Uint64(name, value))
+}
+
+func (m Message) Float(name string, value float64) Message {
+	return m.param(Float(name, value))
+}
+
+func (m Message) Bool(name string, value bool) Message {
+	return m.param(Bool(name, value))
+}
+
+func (m Message) Dur(name string, value time.Duration) Message {
+	return m.param(Dur(name, value))
+}
+
+func (m Message) Time(name string, value time.Time) Message {
+	return m.param(Time(name, value))
+}
+
+func (m Message) Stringer(name string, value fmt.Stringer) Message {
+	return m.param(Stringer(name, value))
+}
+
+func (m Message) Bytes(name string, value []byte) Message {
+	return m.param(Bytes(name, value))
+}
+
 func (m Message) Duration(d time.Duration) Message {
-	m.data.Duration = d
+	if m.data != nil {
+		m.data.Duration = d
+	}
 	return m
 }
 
+// Enabled reports whether the message will be written. Use it to skip preparing arguments of Send.
+func (m Message) Enabled() bool {
+	return m.data != nil
+}
+
 func (m Message) Send(format string, args ...any) {
-	defer dataPool.Put(m.data)
-	if m.data.Level > m.parent.Level() {
+	if m.data == nil {
+		m.discard()
 		return
 	}
+	defer dataPool.Put(m.data)
+	m.data.Format = format
 	m.data.Message = fmt.Appendf(m.data.Message, format, args...)
+	m.write()
+}
+
+// sendText works like Send, but uses text as-is instead of treating it as a format string.
+func (m Message) sendText(text string) {
+	if m.data == nil {
+		m.discard()
+		return
+	}
+	defer dataPool.Put(m.data)
+	m.data.Format = text
+	m.data.Message = append(m.data.Message, text...)
+	m.write()
+}
+
+// discard finishes a message of a disabled level. Fatal messages exit even when they are not written.
+func (m Message) discard() {
+	if m.exitCode != 0 {
+		shutdownAndExit(m.exitCode)
+	}
+}
+
+// write runs engines of the logger. It must be called directly by Send or sendText,
+// because the caller is looked up relative to them.
+func (m Message) write() {
+	if m.data.Time.IsZero() {
+		m.data.Time = time.Now()
+	}
+	if !m.data.Caller.Defined() && m.parent.IncludeCaller() {
+		m.data.Caller = captureCaller(2 + m.data.callerSkip)
+	}
+	resolveValues(m.parent, m.data)
 	m.parent.engine(m.parent, m.data)
 	if m.data.ExitCode != 0 {
-		os.Exit(m.data.ExitCode)
+		shutdownAndExit(m.data.ExitCode)
 	}
 }
 
 var dataPool = gpool.New[MessageData](gpool.OnInit[MessageData](func(m *MessageData) {
-	m.Stack = make([]byte, Config.StackTraceBufferSize())
+	m.Stack.pcs = make([]uintptr, 0, Config.StackTraceFrames())
 	m.Params = make([]Parameter, 0, Config.MessageParametersSliceAllocation())
 	m.Message = make([]byte, 0, Config.MessageBufferSize())
 }), gpool.OnPut[MessageData](func(m *MessageData) {
-	clear(m.Stack)
+	clear(m.Stack.pcs)
 	clear(m.Params)
 	clear(m.Message)
-	m.Stack = m.Stack[:Config.StackTraceBufferSize():Config.StackTraceBufferSize()]
+	m.Stack.pcs = m.Stack.pcs[:0]
 	m.Params = m.Params[:0:Config.MessageParametersSliceAllocation()]
 	m.Message = m.Message[:0:Config.MessageBufferSize()]
 	m.Details = nil
+	m.Error = nil
+	m.Time = time.Time{}
+	m.Context = nil
+	m.Format = ""
+	m.Caller = Caller{}
+	m.callerSkip = 0
+	clear(m.resolvedParams)
+	m.resolvedParams = m.resolvedParams[:0]
+	m.loggerParams = nil
+	m.contextParams = nil
 	m.ExitCode = 0
 	m.Duration = 0
 	m.StackIncluded = false
 }))
 
 func newMessage(log *Logger, level Level) Message {
+	if !log.Enabled(level) {
+		return Message{parent: log}
+	}
 	data := dataPool.Get()
 	data.Level = level
+	data.Context = log.Context()
 	return Message{
 		data:   data,
 		parent: log,
@@ -166,11 +293,18 @@ func newMessage(log *Logger, level Level) Message {
 
 func newErrorMessage(log *Logger, exitCode ...int) Message {
 	message := newMessage(log, inlineif.IfElse(len(exitCode) > 0, LevelPanic, LevelError))
+	if message.data == nil {
+		if len(exitCode) > 0 {
+			message.exitCode = exitCode[0]
+		}
+		return message
+	}
 	if len(exitCode) > 0 {
 		message.data.ExitCode = exitCode[0]
 	}
 	if message.data.Level == LevelPanic || Config.IncludeStackOnError() {
-		message.Stack()
+		// The trace starts at the caller of the function calling newErrorMessage (e.g. Logger.Error).
+		message.stack(2)
 	}
 	return message
 }
@@ -1,6 +1,443 @@
 package golog
 
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// ParamKind tells how the value of a Parameter is stored.
+type ParamKind uint8
+
+const (
+	// KindAny parameters keep their value in Parameter.Value.
+	KindAny ParamKind = iota
+	KindString
+	KindInt
+	KindUint
+	KindFloat
+	KindBool
+	KindDuration
+	KindTime
+	KindError
+	KindStringer
+	KindBytes
+)
+
+// Parameter is a named value attached to a logger or a message. Parameters built with typed constructors
+// (Str, Int, Dur...) keep their value unboxed, so engines encode them without reflection or allocations.
 type Parameter struct {
-	Name  string `json:"name"`
-	Value any    `json:"value"`
+	Name string `json:"name"`
+	// Value holds values of KindAny parameters (e.g. from Param) as well as errors and stringers.
+	// It is nil for other typed parameters; engines should read values with Any, Kind and the typed accessors.
+	Value any `json:"value"`
+
+	kind ParamKind
+	// nsec is the nanosecond of KindTime parameters, whose num holds Unix seconds.
+	nsec int32
+	num  uint64
+	str  string
+	loc  *time.Location
+}
+
+func Str(name, value string) Parameter {
+	return Parameter{Name: name, kind: KindString, str: value}
+}
+
+func Int(name string, value int) Parameter {
+	return Int64(name, int64(value))
+}
+
+func Int64(name string, value int64) Parameter {
+	return Parameter{Name: name, kind: KindInt, num: uint64(value)}
+}
+
+func Uint(name string, value uint) Parameter {
+	return Uint64(name, uint64(value))
+}
+
+func Uint64(name string, value uint64) Parameter {
+	return Parameter{Name: name, kind: KindUint, num: value}
+}
+
+func Float(name string, value float64) Parameter {
+	return Parameter{Name: name, kind: KindFloat, num: math.Float64bits(value)}
+}
+
+func Bool(name string, value bool) Parameter {
+	p := Parameter{Name: name, kind: KindBool}
+	if value {
+		p.num = 1
+	}
+	return p
+}
+
+func Dur(name string, value time.Duration) Parameter {
+	return Parameter{Name: name, kind: KindDuration, num: uint64(value)}
+}
+
+// Time drops the monotonic clock reading of value.
+func Time(name string, value time.Time) Parameter {
+	return Parameter{Name: name, kind: KindTime, num: uint64(value.Unix()), nsec: int32(value.Nanosecond()), loc: value.Location()}
+}
+
+// Err renders err.Error(). Nil errors are rendered as null.
+func Err(name string, err error) Parameter {
+	return Parameter{Name: name, kind: KindError, Value: err}
+}
+
+// Stringer calls value.String() only when the parameter is rendered.
+func Stringer(name string, value fmt.Stringer) Parameter {
+	return Parameter{Name: name, kind: KindStringer, Value: value}
+}
+
+// Bytes renders value as text. It is not copied, so it must not be modified until the message is sent.
+func Bytes(name string, value []byte) Parameter {
+	return Parameter{Name: name, kind: KindBytes, str: unsafe.String(unsafe.SliceData(value), len(value))}
+}
+
+// Any stores common types (strings, integers, floats, bool, time.Duration, time.Time and errors) as typed parameters.
+// Values implementing slog.LogValuer are resolved first. Other values are kept in Value and encoded
+// with fmt or encoding/json.
+func Any(name string, value any) Parameter {
+	switch v := value.(type) {
+	case slog.LogValuer:
+		return slogParam(name, v.LogValue().Resolve())
+	case string:
+		return Str(name, v)
+	case int:
+		return Int64(name, int64(v))
+	case int8:
+		return Int64(name, int64(v))
+	case int16:
+		return Int64(name, int64(v))
+	case int32:
+		return Int64(name, int64(v))
+	case int64:
+		return Int64(name, v)
+	case uint:
+		return Uint64(name, uint64(v))
+	case uint8:
+		return Uint64(name, uint64(v))
+	case uint16:
+		return Uint64(name, uint64(v))
+	case uint32:
+		return Uint64(name, uint64(v))
+	case uint64:
+		return Uint64(name, v)
+	case float32:
+		return Float(name, float64(v))
+	case float64:
+		return Float(name, v)
+	case bool:
+		return Bool(name, v)
+	case time.Duration:
+		return Dur(name, v)
+	case time.Time:
+		return Time(name, v)
+	case error:
+		return Err(name, v)
+	}
+	return Parameter{Name: name, Value: value}
+}
+
+// resolve converts KindAny parameters (from Param or struct literals) into typed ones, resolving slog.LogValuer
+// values. Messages resolve their params, and params of the logger and context, before engines run, so accessors
+// of KindAny parameters only see values that have not been sent.
+func (p Parameter) resolve() Parameter {
+	if p.kind == KindAny && p.Value != nil {
+		return Any(p.Name, p.Value)
+	}
+	return p
+}
+
+// Kind returns how the value is stored. Params of sent messages, including logger and context params read with
+// MessageData.LoggerParams and MessageData.ContextParams, are KindAny only if their value has no typed kind.
+func (p Parameter) Kind() ParamKind {
+	return p.kind
+}
+
+func (p Parameter) Int64() int64 {
+	return int64(p.num)
+}
+
+func (p Parameter) Uint64() uint64 {
+	return p.num
+}
+
+func (p Parameter) Float64() float64 {
+	return math.Float64frombits(p.num)
+}
+
+func (p Parameter) Bool() bool {
+	return p.num == 1
+}
+
+func (p Parameter) Duration() time.Duration {
+	return time.Duration(p.num)
+}
+
+func (p Parameter) Time() time.Time {
+	if p.kind != KindTime {
+		return time.Time{}
+	}
+	return time.Unix(int64(p.num), int64(p.nsec)).In(p.loc)
+}
+
+// Text returns the value of string, bytes, error and stringer parameters.
+func (p Parameter) Text() string {
+	switch p.kind {
+	case KindString, KindBytes:
+		return p.str
+	case KindError:
+		if err, _ := p.Value.(error); err != nil {
+			return err.Error()
+		}
+	case KindStringer:
+		if s, _ := p.Value.(fmt.Stringer); s != nil {
+			return s.String()
+		}
+	}
+	return ""
+}
+
+// Any returns the value boxed, e.g. int64 for KindInt or string for KindBytes.
+func (p Parameter) Any() any {
+	switch p.kind {
+	case KindString, KindBytes:
+		return p.str
+	case KindInt:
+		return int64(p.num)
+	case KindUint:
+		return p.num
+	case KindFloat:
+		return math.Float64frombits(p.num)
+	case KindBool:
+		return p.num == 1
+	case KindDuration:
+		return time.Duration(p.num)
+	case KindTime:
+		return p.Time()
+	}
+	return p.Value
+}
+
+// AppendText appends the value formatted like fmt.Print does, except times use time.RFC3339Nano.
+func (p Parameter) AppendText(dst []byte) []byte {
+	switch p.kind {
+	case KindString, KindBytes:
+		return append(dst, p.str...)
+	case KindInt:
+		return strconv.AppendInt(dst, int64(p.num), 10)
+	case KindUint:
+		return strconv.AppendUint(dst, p.num, 10)
+	case KindFloat:
+		return strconv.AppendFloat(dst, math.Float64frombits(p.num), 'g', -1, 64)
+	case KindBool:
+		return strconv.AppendBool(dst, p.num == 1)
+	case KindDuration:
+		return appendDuration(dst, time.Duration(p.num))
+	case KindTime:
+		return p.Time().AppendFormat(dst, time.RFC3339Nano)
+	case KindError, KindStringer:
+		if p.Value == nil {
+			return append(dst, "<nil>"...)
+		}
+		return append(dst, p.Text()...)
+	}
+	return fmt.Append(dst, p.Value)
+}
+
+// AppendJSON appends the value encoded as JSON. Durations are encoded as strings (e.g. "1.5s"),
+// non-finite floats as strings and KindAny values with encoding/json.
+func (p Parameter) AppendJSON(dst []byte) []byte {
+	switch p.kind {
+	case KindString, KindBytes:
+		return appendJSONString(dst, p.str)
+	case KindInt:
+		return strconv.AppendInt(dst, int64(p.num), 10)
+	case KindUint:
+		return strconv.AppendUint(dst, p.num, 10)
+	case KindFloat:
+		return appendJSONFloat(dst, math.Float64frombits(p.num))
+	case KindBool:
+		return strconv.AppendBool(dst, p.num == 1)
+	case KindDuration:
+		dst = append(dst, '"')
+		dst = appendDuration(dst, time.Duration(p.num))
+		return append(dst, '"')
+	case KindTime:
+		dst = append(dst, '"')
+		dst = p.Time().AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"')
+	case KindError, KindStringer:
+		if p.Value == nil {
+			return append(dst, "null"...)
+		}
+		return appendJSONString(dst, p.Text())
+	}
+	return appendJSONValue(dst, p.Value)
+}
+
+// MarshalJSON encodes the parameter as {"name": ..., "value": ...}.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return appendParamJSON(nil, p), nil
+}
+
+func appendParamJSON(dst []byte, p Parameter) []byte {
+	dst = append(dst, `{"name":`...)
+	dst = appendJSONString(dst, p.Name)
+	dst = append(dst, `,"value":`...)
+	dst = p.AppendJSON(dst)
+	return append(dst, '}')
+}
+
+// appendParamsJSON encodes all params as a single JSON array, or null when every list is nil.
+func appendParamsJSON(dst []byte, params ...[]Parameter) []byte {
+	if !slices.ContainsFunc(params, func(list []Parameter) bool { return list != nil }) {
+		return append(dst, "null"...)
+	}
+	dst = append(dst, '[')
+	first := true
+	for _, list := range params {
+		for _, p := range list {
+			if !first {
+				dst = append(dst, ',')
+			}
+			first = false
+			dst = appendParamJSON(dst, p)
+		}
+	}
+	return append(dst, ']')
+}
+
+// appendJSONString quotes s like encoding/json, including escaping of HTML characters, U+2028 and U+2029.
+func appendJSONString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c == '\n':
+				dst = append(dst, '\\', 'n')
+			case c == '\r':
+				dst = append(dst, '\\', 'r')
+			case c == '\t':
+				dst = append(dst, '\\', 't')
+			case c < 0x20 || c == '<' || c == '>' || c == '&':
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				dst = append(dst, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, `\ufffd`...)
+		} else if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		} else {
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
+
+// appendJSONFloat formats f like encoding/json does. NaN and infinities are encoded as strings.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		dst = append(dst, '"')
+		dst = strconv.AppendFloat(dst, f, 'g', -1, 64)
+		return append(dst, '"')
+	}
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// appendDuration appends d formatted like time.Duration.String, without allocating.
+func appendDuration(dst []byte, d time.Duration) []byte {
+	if d == 0 {
+		return append(dst, "0s"...)
+	}
+	if d < 0 {
+		dst = append(dst, '-')
+	}
+	u := uint64(d)
+	if d < 0 {
+		u = -u
+	}
+	if u < uint64(time.Second) {
+		switch {
+		case u < uint64(time.Microsecond):
+			dst = strconv.AppendUint(dst, u, 10)
+			return append(dst, "ns"...)
+		case u < uint64(time.Millisecond):
+			dst = appendFraction(dst, u, 3)
+			return append(dst, "µs"...)
+		default:
+			dst = appendFraction(dst, u, 6)
+			return append(dst, "ms"...)
+		}
+	}
+	hours := u / uint64(time.Hour)
+	u -= hours * uint64(time.Hour)
+	minutes := u / uint64(time.Minute)
+	u -= minutes * uint64(time.Minute)
+	if hours > 0 {
+		dst = strconv.AppendUint(dst, hours, 10)
+		dst = append(dst, 'h')
+	}
+	if hours > 0 || minutes > 0 {
+		dst = strconv.AppendUint(dst, minutes, 10)
+		dst = append(dst, 'm')
+	}
+	dst = appendFraction(dst, u, 9)
+	return append(dst, 's')
+}
+
+// appendFraction appends v / 10^prec, omitting trailing zeros of the fraction.
+func appendFraction(dst []byte, v uint64, prec int) []byte {
+	var digits [20]byte
+	div := uint64(1)
+	for range prec {
+		div *= 10
+	}
+	dst = strconv.AppendUint(dst, v/div, 10)
+	frac := v % div
+	if frac == 0 {
+		return dst
+	}
+	n := prec
+	for i := prec - 1; i >= 0; i-- {
+		digits[i] = byte('0' + frac%10)
+		frac /= 10
+	}
+	for n > 0 && digits[n-1] == '0' {
+		n--
+	}
+	dst = append(dst, '.')
+	return append(dst, digits[:n]...)
 }
@@ -0,0 +1,149 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedParams(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 0, 500, time.UTC)
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff), ColorEngine(buff))
+	Config.SetDisableTerminalColors(true)
+	defer Config.SetDisableTerminalColors(false)
+
+	log.Info().
+		Str("str", "a\"b").
+		Int("int", -5).
+		Uint64("uint", math.MaxUint64).
+		Float("float", 1.5).
+		Bool("bool", true).
+		Dur("dur", 1500*time.Millisecond).
+		Time("time", at).
+		Stringer("ip", net.IPv4(127, 0, 0, 1)).
+		Bytes("bytes", []byte("raw")).
+		Params(Err("err", errors.New("failure")), Err("nil", nil)).
+		Param("any", []int{1, 2}).
+		Send("typed")
+
+	jsonLine, colorLine, _ := strings.Cut(buff.String(), "\n")
+	var out struct {
+		Params []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal([]byte(jsonLine), &out); err != nil {
+		t.Fatalf("cannot decode output: %v", err)
+	}
+	expected := []string{`"a\"b"`, `-5`, `18446744073709551615`, `1.5`, `true`, `"1.5s"`, `"2024-05-01T12:30:00.0000005Z"`,
+		`"127.0.0.1"`, `"raw"`, `"failure"`, `null`, `[1,2]`}
+	if len(out.Params) != len(expected) {
+		t.Fatalf("expected %d params, got %d", len(expected), len(out.Params))
+	}
+	for i, param := range out.Params {
+		if string(param.Value) != expected[i] {
+			t.Errorf("param %s: expected %s, got %s", param.Name, expected[i], param.Value)
+		}
+	}
+	if !strings.Contains(colorLine, "| str(a\"b) int(-5) uint(18446744073709551615) float(1.5) bool(true) dur(1.5s) "+
+		"time(2024-05-01T12:30:00.0000005Z) ip(127.0.0.1) bytes(raw) err(failure) nil(<nil>) any([1 2]) -> typed") {
+		t.Errorf("unexpected color output: %s", colorLine)
+	}
+}
+
+func TestParamLiteral(t *testing.T) {
+	p := Parameter{Name: "n", Value: 42}
+	if p.Any() != 42 || string(p.AppendJSON(nil)) != "42" {
+		t.Errorf("expected the original value, got %v", p.Any())
+	}
+	var kinds []ParamKind
+	log := New("app", func(log *Logger, data *MessageData) {
+		for _, params := range [3][]Parameter{data.LoggerParams(), data.ContextParams(), data.Params} {
+			for _, param := range params {
+				kinds = append(kinds, param.Kind())
+			}
+		}
+	}).With(p)
+	log.Info().Ctx(WithParams(context.Background(), p)).Params(p).Send("literal")
+	if !slices.Equal(kinds, []ParamKind{KindInt, KindInt, KindInt}) {
+		t.Errorf("literal parameters were not resolved: %v", kinds)
+	}
+}
+
+func TestAnyDuration(t *testing.T) {
+	if p := Any("d", 1500*time.Millisecond); p.Kind() != KindDuration || p.Duration() != 1500*time.Millisecond {
+		t.Errorf("unexpected duration param: %v %v", p.Kind(), p.Duration())
+	}
+	if p := Any("f", float32(0.5)); p.Kind() != KindFloat || p.Float64() != 0.5 {
+		t.Errorf("unexpected float32 param: %v %v", p.Kind(), p.Float64())
+	}
+	jsonOut, logfmtOut := new(bytes.Buffer), new(bytes.Buffer)
+	New("app", JSONEngine(jsonOut), LogfmtEngine(logfmtOut)).Info().Param("d", 1500*time.Millisecond).Send("done")
+	if !strings.Contains(jsonOut.String(), `{"name":"d","value":"1.5s"}`) || !strings.Contains(logfmtOut.String(), " d=1.5s") {
+		t.Errorf("durations differ between engines: %s %s", jsonOut, logfmtOut)
+	}
+}
+
+func TestTimeParamRange(t *testing.T) {
+	for _, at := range []time.Time{
+		{},
+		time.Date(1500, 1, 2, 3, 4, 5, 6, time.UTC),
+		time.Date(3000, 1, 2, 3, 4, 5, 999999999, time.FixedZone("X", 3600)),
+	} {
+		if got := Time("t", at).Time(); !got.Equal(at) || got.Location().String() != at.Location().String() {
+			t.Errorf("expected %s, got %s", at, got)
+		}
+	}
+	if !Time("t", time.Time{}).Time().IsZero() {
+		t.Error("zero time was not preserved")
+	}
+}
+
+func TestAppendDuration(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 999, 1500, time.Millisecond + 20, 1500 * time.Millisecond, -time.Minute,
+		90 * time.Minute, 26*time.Hour + 3*time.Second + 7, math.MaxInt64, math.MinInt64} {
+		if got := string(appendDuration(nil, d)); got != d.String() {
+			t.Errorf("expected %s, got %s", d.String(), got)
+		}
+	}
+}
+
+func TestAppendJSONFloat(t *testing.T) {
+	for _, f := range []float64{0, 1, -2.5, 1e-7, 1.5e-9, 123456789, 1e21, 3.14159} {
+		expected, _ := json.Marshal(f)
+		if got := string(appendJSONFloat(nil, f)); got != string(expected) {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	}
+}
+
+var raceEnabled bool
+
+func TestTypedParamsAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not measured with the race detector")
+	}
+	for name, engine := range map[string]WriteEngine{
+		"color": ColorEngine(io.Discard), "json": JSONEngine(io.Discard), "logfmt": LogfmtEngine(io.Discard),
+		"text": TextEngine(nil, io.Discard), "cbor": CBOREngine(io.Discard), "msgpack": MsgpackEngine(io.Discard),
+	} {
+		log := New("app", engine)
+		allocs := testing.AllocsPerRun(100, func() {
+			log.Info().Str("str", "value").Int("int", 5).Float("float", 1.5).Bool("bool", true).
+				Dur("dur", time.Second).Send("typed")
+		})
+		if allocs != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestDisabledMessage(t *testing.T) {
+	written := 0
+	log := New("app", func(log *Logger, data *MessageData) {
+		written++
+	}).SetLevel(LevelWarning)
+	Config.SetIncludeStackOnError(true)
+	defer Config.SetIncludeStackOnError(false)
+
+	if log.Enabled(LevelDebug) || !log.Enabled(LevelWarning) {
+		t.Errorf("unexpected enabled levels")
+	}
+	if msg := log.Debug(); msg.Enabled() || msg.data != nil {
+		t.Errorf("disabled message has data")
+	}
+	log.Debug().Str("str", "value").Param("any", 1).Details("details").Stack().Send("%d", 1)
+	log.Info().Throw(errors.New("failure"))
+	if written != 0 {
+		t.Errorf("expected no messages, got %d", written)
+	}
+
+	if !raceEnabled {
+		allocs := testing.AllocsPerRun(100, func() {
+			log.Trace().Str("str", "value").Int("int", 1).Stack().Send("trace")
+		})
+		if allocs != 0 {
+			t.Errorf("expected no allocations, got %v", allocs)
+		}
+	}
+
+	// no level is below LevelPanic, so every message is filtered
+	log.SetLevel(LevelPanic - 1)
+	var exitCodes []int
+	exit = func(code int) {
+		exitCodes = append(exitCodes, code)
+	}
+	defer func() {
+		exit = os.Exit
+	}()
+	log.Error().Send("error")
+	log.Fatal(2).Send("fatal")
+	log.Fatal(3).Throw(errors.New("fatal"))
+	if written != 0 || len(exitCodes) != 2 || exitCodes[0] != 2 || exitCodes[1] != 3 {
+		t.Errorf("expected filtered fatal messages to exit, got %d messages and exit codes %v", written, exitCodes)
+	}
+}
+
+func TestThrowMessageIsNotFormat(t *testing.T) {
+	var message string
+	log := New("app", func(log *Logger, data *MessageData) {
+		message = string(data.Message)
+	})
+	log.Error().Throw(errors.New("disk 100% full"))
+	if message != "disk 100% full" {
+		t.Errorf("error message was treated as a format string: %q", message)
+	}
+}
@@ -0,0 +1,142 @@
+package golog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ModuleLevel sets the minimum message level of loggers whose module path matches Pattern.
+//
+// Module path is made of logger modules joined with "/" (without scopes), e.g. "app/db".
+// Pattern matches the path itself and all of its submodules; "*" matches every module.
+// When many patterns match, the longest one wins.
+type ModuleLevel struct {
+	Pattern string
+	Level   Level
+}
+
+func (m ModuleLevel) matches(path string) bool {
+	if m.Pattern == "*" {
+		return true
+	}
+	pattern := strings.TrimSuffix(m.Pattern, "/*")
+	return path == pattern || strings.HasPrefix(path, pattern+"/")
+}
+
+func (m ModuleLevel) specificity() int {
+	if m.Pattern == "*" {
+		return 0
+	}
+	return len(strings.TrimSuffix(m.Pattern, "/*")) + 1
+}
+
+// ParseModuleLevels parses a comma separated list of pattern=level pairs, e.g. "app/db=debug, app/http=warning, *=info".
+func ParseModuleLevels(spec string) ([]ModuleLevel, error) {
+	var levels []ModuleLevel
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid module level %q: expected pattern=level", entry)
+		}
+		level := levelFromString(strings.TrimSpace(name))
+		if level == 0 {
+			return nil, fmt.Errorf("invalid module level %q: unknown level %q", entry, name)
+		}
+		levels = append(levels, ModuleLevel{
+			Pattern: strings.Trim(strings.TrimSpace(pattern), "/"),
+			Level:   level,
+		})
+	}
+	return levels, nil
+}
+
+func (o *globalOptions) ModuleLevels() []ModuleLevel {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	return slices.Clone(o.moduleLevels)
+}
+
+// ModuleLevel returns the level configured for the module path, if any pattern matches it.
+func (o *globalOptions) ModuleLevel(path string) (Level, bool) {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	for _, level := range o.moduleLevels {
+		if level.matches(path) {
+			return level.Level, true
+		}
+	}
+	return 0, false
+}
+
+// SetModuleLevels replaces all module levels.
+func (o *globalOptions) SetModuleLevels(levels ...ModuleLevel) {
+	levels = slices.Clone(levels)
+	sortModuleLevels(levels)
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.moduleLevels = levels
+	o.levelVersion.Add(1)
+}
+
+// SetModuleLevel sets (or with level 0 removes) the level of a single pattern.
+func (o *globalOptions) SetModuleLevel(pattern string, level Level) {
+	pattern = strings.Trim(pattern, "/")
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	levels := slices.DeleteFunc(slices.Clone(o.moduleLevels), func(m ModuleLevel) bool {
+		return m.Pattern == pattern
+	})
+	if level != 0 {
+		levels = append(levels, ModuleLevel{Pattern: pattern, Level: level})
+	}
+	sortModuleLevels(levels)
+	o.moduleLevels = levels
+	o.levelVersion.Add(1)
+}
+
+// sortModuleLevels puts the most specific patterns first, as they have to be checked first.
+func sortModuleLevels(levels []ModuleLevel) {
+	slices.SortStableFunc(levels, func(a, b ModuleLevel) int {
+		return b.specificity() - a.specificity()
+	})
+}
+
+// configuredLevel returns the override level or the module level of path, if any is set.
+func (o *globalOptions) configuredLevel(path string) (Level, bool) {
+	o.mut.RLock()
+	defer o.mut.RUnlock()
+	if o.overrideMinimumMessageLevel != 0 {
+		return o.overrideMinimumMessageLevel, true
+	}
+	for _, level := range o.moduleLevels {
+		if level.matches(path) {
+			return level.Level, true
+		}
+	}
+	return 0, false
+}
+
+// levelCache is the level set for a logger by Config, valid as long as Config.levelVersion equals version.
+type levelCache struct {
+	version uint64
+	level   Level
+	ok      bool
+}
+
+// modulePath joins module names without their scopes.
+func modulePath(modules []string) string {
+	var b strings.Builder
+	for i, module := range modules {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		name, _, _ := strings.Cut(module, "@")
+		b.WriteString(name)
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package golog
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels("app/db=debug, app/http=warning, *=info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Config.SetModuleLevels(levels...)
+	defer Config.SetModuleLevels()
+
+	app := New("app", ColorEngine(io.Discard)).SetLevel(LevelError)
+	cases := []struct {
+		log   *Logger
+		level Level
+	}{
+		{app, LevelInfo},
+		{app.Module("db"), LevelDebug},
+		{app.Module("db", "users").Module("pool"), LevelDebug},
+		{app.Module("http"), LevelWarning},
+		{app.Module("dbx"), LevelInfo},
+	}
+	for _, c := range cases {
+		if level := c.log.Level(); level != c.level {
+			t.Errorf("%s: expected %s, got %s", c.log.ModulePath(), c.level, level)
+		}
+	}
+
+	Config.SetModuleLevel("app/db/pool", LevelTrace)
+	if level := app.Module("db").Module("pool").Level(); level != LevelTrace {
+		t.Errorf("expected TRACE, got %s", level)
+	}
+}
+
+func TestParseModuleLevelsInvalid(t *testing.T) {
+	for _, spec := range []string{"app", "app=loud"} {
+		if _, err := ParseModuleLevels(spec); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestSetModuleLevelConcurrent(t *testing.T) {
+	defer Config.SetModuleLevels()
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Config.SetModuleLevel("module"+strconv.Itoa(i), LevelDebug)
+		}()
+	}
+	wg.Wait()
+	if levels := Config.ModuleLevels(); len(levels) != 50 {
+		t.Errorf("expected 50 module levels, got %d", len(levels))
+	}
+}
+
+func TestModuleLevelCache(t *testing.T) {
+	defer Config.SetModuleLevels()
+	log := New("app", ColorEngine(io.Discard)).Module("db")
+	child := log.withContext(context.Background())
+	if log.Enabled(LevelDebug) || child.Enabled(LevelDebug) {
+		t.Fatal("debug is enabled by default")
+	}
+	Config.SetModuleLevel("app/db", LevelDebug)
+	if !log.Enabled(LevelDebug) || !child.Enabled(LevelDebug) {
+		t.Error("cached level was not updated after SetModuleLevel")
+	}
+	Config.SetOverrideMinimumMessageLevel(LevelError)
+	if log.Enabled(LevelWarning) {
+		t.Error("cached level was not updated after SetOverrideMinimumMessageLevel")
+	}
+	Config.SetOverrideMinimumMessageLevel(0)
+	Config.SetModuleLevel("app/db", 0)
+	if log.Enabled(LevelDebug) {
+		t.Error("cached level was not updated after removing the module level")
+	}
+}
@@ -0,0 +1,112 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/BOOMfinity/golog/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceExtractor reads the span context of an OpenTelemetry span stored in ctx.
+func TraceExtractor(ctx context.Context) (golog.TraceInfo, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return golog.TraceInfo{}, false
+	}
+	return golog.TraceInfo{
+		TraceID: sc.TraceID(),
+		SpanID:  sc.SpanID(),
+		Flags:   byte(sc.TraceFlags()),
+	}, true
+}
+
+// Install makes golog engines write trace and span ids of OpenTelemetry spans found in message contexts.
+func Install() {
+	golog.Config.SetTraceExtractor(TraceExtractor)
+}
+
+// SpanEventsEngine records messages with given levels (panic and error by default) as events
+// of the span found in the message context. Messages with an error are recorded as exceptions.
+func SpanEventsEngine(levels ...golog.Level) golog.WriteEngine {
+	if len(levels) == 0 {
+		levels = append(levels, golog.LevelPanic, golog.LevelError)
+	}
+	return func(log *golog.Logger, data *golog.MessageData) {
+		if data.Context == nil || !slices.Contains(levels, data.Level) {
+			return
+		}
+		span := trace.SpanFromContext(data.Context)
+		if !span.IsRecording() {
+			return
+		}
+		attrs := make([]attribute.KeyValue, 0, len(data.Params)+3)
+		attrs = append(attrs,
+			attribute.String("log.severity", data.Level.String()),
+			attribute.String("log.message", string(data.Message)),
+			attribute.StringSlice("golog.module", log.Modules()),
+		)
+		for _, param := range data.Params {
+			attrs = append(attrs, Attribute(param.Name, param.Any()))
+		}
+		if data.Error != nil {
+			span.RecordError(data.Error, trace.WithTimestamp(data.Time), trace.WithAttributes(attrs...))
+			return
+		}
+		span.AddEvent("log", trace.WithTimestamp(data.Time), trace.WithAttributes(attrs...))
+	}
+}
+
+// Attribute converts a golog parameter value to an OpenTelemetry attribute.
+func Attribute(name string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(name, v)
+	case bool:
+		return attribute.Bool(name, v)
+	case int:
+		return attribute.Int(name, v)
+	case int8:
+		return attribute.Int64(name, int64(v))
+	case int16:
+		return attribute.Int64(name, int64(v))
+	case int32:
+		return attribute.Int64(name, int64(v))
+	case int64:
+		return attribute.Int64(name, v)
+	case uint:
+		return Attribute(name, uint64(v))
+	case uint8:
+		return attribute.Int64(name, int64(v))
+	case uint16:
+		return attribute.Int64(name, int64(v))
+	case uint32:
+		return attribute.Int64(name, int64(v))
+	case uint64:
+		if v > math.MaxInt64 {
+			return attribute.String(name, strconv.FormatUint(v, 10))
+		}
+		return attribute.Int64(name, int64(v))
+	case float64:
+		return attribute.Float64(name, v)
+	case float32:
+		return attribute.Float64(name, float64(v))
+	case time.Duration:
+		return attribute.String(name, v.String())
+	case time.Time:
+		return attribute.String(name, v.Format(time.RFC3339Nano))
+	case []string:
+		return attribute.StringSlice(name, v)
+	case fmt.Stringer:
+		return attribute.String(name, v.String())
+	case error:
+		return attribute.String(name, v.Error())
+	default:
+		return attribute.String(name, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,74 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/BOOMfinity/golog/v2"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanEventsEngine(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+
+	log := golog.New("app", SpanEventsEngine()).Module("db")
+	log.Info().Ctx(ctx).Send("ignored")
+	log.Error().Ctx(ctx).Param("table", "users").Send("query failed")
+	log.Error().Ctx(ctx).Err(errors.New("timeout")).Send("connection lost")
+	log.Error().Send("no span")
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	events := spans[0].Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Name != "log" || !hasAttribute(events[0].Attributes, attribute.String("log.message", "query failed")) ||
+		!hasAttribute(events[0].Attributes, attribute.String("log.severity", "ERROR")) ||
+		!hasAttribute(events[0].Attributes, attribute.StringSlice("golog.module", []string{"app", "db"})) ||
+		!hasAttribute(events[0].Attributes, attribute.String("table", "users")) {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+	if events[1].Name != "exception" || !hasAttribute(events[1].Attributes, attribute.String("exception.message", "timeout")) ||
+		!hasAttribute(events[1].Attributes, attribute.String("log.message", "connection lost")) {
+		t.Errorf("unexpected exception: %+v", events[1])
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, expected attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr.Key == expected.Key && attr.Value == expected.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAttribute(t *testing.T) {
+	for _, test := range []struct {
+		value    any
+		expected attribute.Value
+	}{
+		{int8(-8), attribute.Int64Value(-8)},
+		{int16(-16), attribute.Int64Value(-16)},
+		{int32(-32), attribute.Int64Value(-32)},
+		{uint(5), attribute.Int64Value(5)},
+		{uint8(8), attribute.Int64Value(8)},
+		{uint16(16), attribute.Int64Value(16)},
+		{uint64(1 << 63), attribute.StringValue("9223372036854775808")},
+		{float32(0.5), attribute.Float64Value(0.5)},
+		{[]string{"a"}, attribute.StringSliceValue([]string{"a"})},
+	} {
+		if attr := Attribute("name", test.value); attr.Value != test.expected {
+			t.Errorf("%T: expected %v, got %v", test.value, test.expected.Emit(), attr.Value.Emit())
+		}
+	}
+}
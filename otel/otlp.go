@@ -0,0 +1,484 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BOOMfinity/golog/v2"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encoding is the body format used to send logs over OTLP/HTTP.
+type Encoding uint8
+
+const (
+	EncodingProtobuf Encoding = iota
+	EncodingJSON
+)
+
+const scopeVersion = "v2"
+
+type OTLPOptions struct {
+	// Endpoint is the full URL of the logs endpoint. Defaults to http://localhost:4318/v1/logs.
+	Endpoint string
+	Encoding Encoding
+	// Headers are added to every request (e.g. authorization).
+	Headers map[string]string
+	// Client defaults to a client with 10 seconds timeout.
+	Client *http.Client
+	// Resource attributes, e.g. service.name.
+	Resource map[string]any
+	// BatchSize is the number of records that triggers an export. Defaults to 512.
+	BatchSize int
+	// MaxQueueSize is the number of records kept while the collector is unavailable. Records of failed exports
+	// are queued again, and the oldest records above the limit are dropped. Defaults to 8192.
+	MaxQueueSize int
+	// FlushInterval is the maximum time records wait before being exported. Defaults to 5 seconds.
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries of a failed export. Defaults to 5.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry. It is doubled after every attempt, up to 30 seconds. Defaults to 500ms.
+	RetryBackoff time.Duration
+	// OnError is called when an export fails after all retries. Records rejected by the collector are dropped,
+	// others are exported again with the next batch.
+	OnError func(err error)
+}
+
+type exporter struct {
+	opts     OTLPOptions
+	resource *resourcepb.Resource
+	mut      sync.Mutex
+	records  []pendingRecord
+	// cancelExport cancels the export started by run, so Flush does not wait for its retries.
+	cancelExport context.CancelFunc
+	// sending holds a token while records are exported; waiting for it respects the context.
+	sending chan struct{}
+	trigger chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type pendingRecord struct {
+	scope  string
+	record *logspb.LogRecord
+}
+
+// NewOTLPEngine creates an engine exporting messages as OTLP log records over HTTP.
+// Records are batched and exported in the background; call golog.Shutdown before the application exits.
+func NewOTLPEngine(opts OTLPOptions) (golog.WriteEngine, error) {
+	if opts.Endpoint == "" {
+		opts.Endpoint = "http://localhost:4318/v1/logs"
+	}
+	if _, err := url.ParseRequestURI(opts.Endpoint); err != nil {
+		return nil, fmt.Errorf("invalid otlp endpoint: %w", err)
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.MaxQueueSize < opts.BatchSize {
+		opts.MaxQueueSize = max(8192, opts.BatchSize)
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 5
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	e := &exporter{
+		opts:     opts,
+		resource: &resourcepb.Resource{Attributes: keyValues(opts.Resource, 0)},
+		sending:  make(chan struct{}, 1),
+		trigger:  make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return golog.Managed(e.write, golog.EngineHooks{
+		Flush: e.flush,
+		Close: e.close,
+	}), nil
+}
+
+func (e *exporter) write(log *golog.Logger, data *golog.MessageData) {
+	record := e.record(log, data)
+	e.mut.Lock()
+	if len(e.records) >= e.opts.MaxQueueSize {
+		e.records = e.records[1:]
+	}
+	e.records = append(e.records, pendingRecord{scope: validUTF8(strings.Join(log.Modules(), "/")), record: record})
+	full := len(e.records) >= e.opts.BatchSize
+	e.mut.Unlock()
+	if full {
+		select {
+		case e.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (e *exporter) record(log *golog.Logger, data *golog.MessageData) *logspb.LogRecord {
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(data.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       severity(data.Level),
+		SeverityText:         validUTF8(data.Level.String()),
+		Body:                 stringValue(string(data.Message)),
+	}
+	for _, param := range data.LoggerParams() {
+		record.Attributes = append(record.Attributes, keyValue(param.Name, param.Any()))
+	}
+	for _, param := range data.ContextParams() {
+		record.Attributes = append(record.Attributes, keyValue(param.Name, param.Any()))
+	}
+	for _, param := range data.Params {
+		record.Attributes = append(record.Attributes, keyValue(param.Name, param.Any()))
+	}
+	if data.Duration > 0 {
+		record.Attributes = append(record.Attributes, keyValue("duration_ms", data.Duration.Milliseconds()))
+	}
+	if data.Details != nil {
+		record.Attributes = append(record.Attributes, keyValue("details", data.Details))
+	}
+	if data.Error != nil {
+		record.Attributes = append(record.Attributes,
+			keyValue("exception.type", fmt.Sprintf("%T", data.Error)),
+			keyValue("exception.message", data.Error.Error()),
+		)
+	}
+	if data.StackIncluded {
+		record.Attributes = append(record.Attributes, keyValue("exception.stacktrace", data.Stack.String()))
+	}
+	if data.Caller.Defined() {
+		record.Attributes = append(record.Attributes,
+			keyValue("code.function", data.Caller.Function),
+			keyValue("code.filepath", data.Caller.File),
+			keyValue("code.lineno", data.Caller.Line),
+		)
+	}
+	if data.Context != nil {
+		if info, ok := TraceExtractor(data.Context); ok {
+			record.TraceId = info.TraceID[:]
+			record.SpanId = info.SpanID[:]
+			record.Flags = uint32(info.Flags)
+		}
+	}
+	return record
+}
+
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-e.trigger:
+		case <-e.stop:
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		e.mut.Lock()
+		e.cancelExport = cancel
+		e.mut.Unlock()
+		go func() {
+			select {
+			case <-e.stop:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		if err := e.exportPending(ctx); err != nil && e.opts.OnError != nil && !errors.Is(err, context.Canceled) {
+			e.opts.OnError(err)
+		}
+		cancel()
+	}
+}
+
+// flush exports all pending records. It cancels the export started in the background, whose records are
+// queued again, instead of waiting for its retries against a failing collector.
+func (e *exporter) flush(ctx context.Context) error {
+	e.mut.Lock()
+	if e.cancelExport != nil {
+		e.cancelExport()
+	}
+	e.mut.Unlock()
+	return e.exportPending(ctx)
+}
+
+// exportPending exports all pending records in batches, one export at a time.
+func (e *exporter) exportPending(ctx context.Context) error {
+	select {
+	case e.sending <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-e.sending }()
+	for {
+		e.mut.Lock()
+		n := min(len(e.records), e.opts.BatchSize)
+		batch := make([]pendingRecord, n)
+		copy(batch, e.records)
+		e.records = e.records[n:]
+		e.mut.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if err := e.export(ctx, batch); err != nil {
+			var permanent permanentError
+			if !errors.As(err, &permanent) {
+				e.requeue(batch)
+			}
+			return err
+		}
+	}
+}
+
+// requeue puts a failed batch back before records written since, dropping the oldest records above MaxQueueSize.
+func (e *exporter) requeue(batch []pendingRecord) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	records := append(batch, e.records...)
+	if over := len(records) - e.opts.MaxQueueSize; over > 0 {
+		records = records[over:]
+	}
+	e.records = records
+}
+
+func (e *exporter) close(ctx context.Context) error {
+	close(e.stop)
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *exporter) export(ctx context.Context, batch []pendingRecord) error {
+	body, contentType, err := e.encode(batch)
+	if err != nil {
+		return permanentError{err: fmt.Errorf("cannot encode otlp request: %w", err)}
+	}
+	backoff := e.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := e.send(ctx, body, contentType)
+		if err == nil {
+			return nil
+		}
+		var permanent permanentError
+		if errors.As(err, &permanent) || attempt >= e.opts.MaxRetries {
+			return err
+		}
+		wait := max(backoff, retryAfter)
+		backoff = min(backoff*2, 30*time.Second)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func (e *exporter) send(ctx context.Context, body []byte, contentType string) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.opts.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range e.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	res, err := e.opts.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("otlp request failed: %w", err)
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return 0, nil
+	}
+	err = fmt.Errorf("otlp collector responded with %s", res.Status)
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		var retryAfter time.Duration
+		if seconds, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, err
+	default:
+		return 0, permanentError{err: err}
+	}
+}
+
+func (e *exporter) encode(batch []pendingRecord) ([]byte, string, error) {
+	resourceLogs := &logspb.ResourceLogs{Resource: e.resource}
+	scopes := map[string]*logspb.ScopeLogs{}
+	for _, pending := range batch {
+		scope, ok := scopes[pending.scope]
+		if !ok {
+			scope = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: pending.scope, Version: scopeVersion}}
+			scopes[pending.scope] = scope
+			resourceLogs.ScopeLogs = append(resourceLogs.ScopeLogs, scope)
+		}
+		scope.LogRecords = append(scope.LogRecords, pending.record)
+	}
+	// LogsData is wire compatible with ExportLogsServiceRequest.
+	data := &logspb.LogsData{ResourceLogs: []*logspb.ResourceLogs{resourceLogs}}
+	if e.opts.Encoding == EncodingJSON {
+		return appendLogsJSON(nil, data), "application/json", nil
+	}
+	body, err := proto.Marshal(data)
+	return body, "application/x-protobuf", err
+}
+
+// severity uses the OpenTelemetry severity number of the level, so custom levels registered in golog are supported.
+func severity(level golog.Level) logspb.SeverityNumber {
+	return logspb.SeverityNumber(level.Severity())
+}
+
+// maxValueDepth limits the nesting of []any and map[string]any converted to OTLP values. Deeper values are
+// written as JSON strings, so cyclic values end with an "!error: ..." string instead of a stack overflow.
+const maxValueDepth = 32
+
+func keyValues(values map[string]any, depth int) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(values))
+	for key, value := range values {
+		kvs = append(kvs, &commonpb.KeyValue{Key: validUTF8(key), Value: nestedValue(value, depth)})
+	}
+	return kvs
+}
+
+func keyValue(key string, value any) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: validUTF8(key), Value: anyValue(value)}
+}
+
+// validUTF8 replaces invalid UTF-8 with U+FFFD. proto.Marshal rejects invalid strings, which would fail
+// the export of the whole batch.
+func validUTF8(s string) string {
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: validUTF8(s)}}
+}
+
+func anyValue(value any) *commonpb.AnyValue {
+	return nestedValue(value, 0)
+}
+
+func nestedValue(value any, depth int) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return stringValue(v)
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int8:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int16:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case uint:
+		return nestedValue(uint64(v), depth)
+	case uint8:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case uint16:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case uint32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case uint64:
+		if v > math.MaxInt64 {
+			return stringValue(strconv.FormatUint(v, 10))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case float32:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []byte:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v}}
+	case time.Duration:
+		return stringValue(v.String())
+	case time.Time:
+		return stringValue(v.Format(time.RFC3339Nano))
+	case []string:
+		values := make([]*commonpb.AnyValue, len(v))
+		for i := range v {
+			values[i] = anyValue(v[i])
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case []any:
+		if depth >= maxValueDepth {
+			return jsonValue(v)
+		}
+		values := make([]*commonpb.AnyValue, len(v))
+		for i := range v {
+			values[i] = nestedValue(v[i], depth+1)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]any:
+		if depth >= maxValueDepth {
+			return jsonValue(v)
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: keyValues(v, depth+1)}}}
+	case error:
+		return stringValue(v.Error())
+	case fmt.Stringer:
+		return stringValue(v.String())
+	default:
+		if encoded, err := json.Marshal(v); err == nil {
+			return stringValue(string(encoded))
+		}
+		return stringValue(fmt.Sprint(v))
+	}
+}
+
+// jsonValue writes value as a JSON string. Values json.Marshal rejects, including cycles, are written as
+// "!error: ..." strings like in golog.JSONEngine.
+func jsonValue(value any) *commonpb.AnyValue {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return stringValue("!error: " + err.Error())
+	}
+	return stringValue(string(encoded))
+}
@@ -0,0 +1,136 @@
+package otel
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"strconv"
+
+	"github.com/BOOMfinity/golog/v2"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+)
+
+// appendLogsJSON encodes data using the OTLP/JSON mapping. It differs from protojson:
+// trace and span ids are hex encoded and 64-bit integers are written as strings.
+func appendLogsJSON(dst []byte, data *logspb.LogsData) []byte {
+	dst = append(dst, `{"resourceLogs":[`...)
+	for i, rl := range data.ResourceLogs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"resource":{"attributes":`...)
+		dst = appendKeyValuesJSON(dst, rl.GetResource().GetAttributes())
+		dst = append(dst, `},"scopeLogs":[`...)
+		for j, sl := range rl.ScopeLogs {
+			if j > 0 {
+				dst = append(dst, ',')
+			}
+			dst = append(dst, `{"scope":{"name":`...)
+			dst = appendJSONString(dst, sl.GetScope().GetName())
+			dst = append(dst, `,"version":`...)
+			dst = appendJSONString(dst, sl.GetScope().GetVersion())
+			dst = append(dst, `},"logRecords":[`...)
+			for k, record := range sl.LogRecords {
+				if k > 0 {
+					dst = append(dst, ',')
+				}
+				dst = appendLogRecordJSON(dst, record)
+			}
+			dst = append(dst, "]}"...)
+		}
+		dst = append(dst, "]}"...)
+	}
+	return append(dst, "]}"...)
+}
+
+func appendLogRecordJSON(dst []byte, record *logspb.LogRecord) []byte {
+	dst = append(dst, `{"timeUnixNano":"`...)
+	dst = strconv.AppendUint(dst, record.TimeUnixNano, 10)
+	dst = append(dst, `","observedTimeUnixNano":"`...)
+	dst = strconv.AppendUint(dst, record.ObservedTimeUnixNano, 10)
+	dst = append(dst, `","severityNumber":`...)
+	dst = strconv.AppendInt(dst, int64(record.SeverityNumber), 10)
+	dst = append(dst, `,"severityText":`...)
+	dst = appendJSONString(dst, record.SeverityText)
+	dst = append(dst, `,"body":`...)
+	dst = appendAnyValueJSON(dst, record.Body)
+	dst = append(dst, `,"attributes":`...)
+	dst = appendKeyValuesJSON(dst, record.Attributes)
+	if len(record.TraceId) > 0 {
+		dst = append(dst, `,"traceId":"`...)
+		dst = hex.AppendEncode(dst, record.TraceId)
+		dst = append(dst, `","spanId":"`...)
+		dst = hex.AppendEncode(dst, record.SpanId)
+		dst = append(dst, `","flags":`...)
+		dst = strconv.AppendUint(dst, uint64(record.Flags), 10)
+	}
+	return append(dst, '}')
+}
+
+func appendKeyValuesJSON(dst []byte, kvs []*commonpb.KeyValue) []byte {
+	dst = append(dst, '[')
+	for i, kv := range kvs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, `{"key":`...)
+		dst = appendJSONString(dst, kv.Key)
+		dst = append(dst, `,"value":`...)
+		dst = appendAnyValueJSON(dst, kv.Value)
+		dst = append(dst, '}')
+	}
+	return append(dst, ']')
+}
+
+func appendAnyValueJSON(dst []byte, value *commonpb.AnyValue) []byte {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		dst = append(dst, `{"stringValue":`...)
+		dst = appendJSONString(dst, v.StringValue)
+	case *commonpb.AnyValue_BoolValue:
+		dst = append(dst, `{"boolValue":`...)
+		dst = strconv.AppendBool(dst, v.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		dst = append(dst, `{"intValue":"`...)
+		dst = strconv.AppendInt(dst, v.IntValue, 10)
+		dst = append(dst, '"')
+	case *commonpb.AnyValue_DoubleValue:
+		dst = append(dst, `{"doubleValue":`...)
+		switch {
+		case math.IsNaN(v.DoubleValue):
+			dst = append(dst, `"NaN"`...)
+		case math.IsInf(v.DoubleValue, 1):
+			dst = append(dst, `"Infinity"`...)
+		case math.IsInf(v.DoubleValue, -1):
+			dst = append(dst, `"-Infinity"`...)
+		default:
+			dst = strconv.AppendFloat(dst, v.DoubleValue, 'g', -1, 64)
+		}
+	case *commonpb.AnyValue_BytesValue:
+		dst = append(dst, `{"bytesValue":"`...)
+		dst = base64.StdEncoding.AppendEncode(dst, v.BytesValue)
+		dst = append(dst, '"')
+	case *commonpb.AnyValue_ArrayValue:
+		dst = append(dst, `{"arrayValue":{"values":[`...)
+		for i, item := range v.ArrayValue.GetValues() {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = appendAnyValueJSON(dst, item)
+		}
+		dst = append(dst, "]}"...)
+	case *commonpb.AnyValue_KvlistValue:
+		dst = append(dst, `{"kvlistValue":{"values":`...)
+		dst = appendKeyValuesJSON(dst, v.KvlistValue.GetValues())
+		dst = append(dst, '}')
+	default:
+		return append(dst, "{}"...)
+	}
+	return append(dst, '}')
+}
+
+// appendJSONString uses the escaping of golog, which writes invalid UTF-8 as \ufffd.
+func appendJSONString(dst []byte, s string) []byte {
+	return golog.Str("", s).AppendJSON(dst)
+}
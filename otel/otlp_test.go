@@ -0,0 +1,216 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BOOMfinity/golog/v2"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type collector struct {
+	mut      sync.Mutex
+	failures int
+	bodies   [][]byte
+	types    []string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	c.bodies = append(c.bodies, body)
+	c.types = append(c.types, r.Header.Get("Content-Type"))
+}
+
+func TestOTLPEngineProtobuf(t *testing.T) {
+	c := &collector{failures: 2}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	engine, err := NewOTLPEngine(OTLPOptions{
+		Endpoint:     server.URL + "/v1/logs",
+		RetryBackoff: time.Millisecond,
+		Resource:     map[string]any{"service.name": "test"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := golog.New("app", engine).Module("db")
+	log.Warn().Param("table", "users").Send("slow query")
+	log.Error().Send("failed")
+	log.Info().Param("bad\xff", "a\xffb").Send("invalid \xff")
+	if err = log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.bodies) != 1 || c.types[0] != "application/x-protobuf" {
+		t.Fatalf("expected 1 protobuf request, got %d %v", len(c.bodies), c.types)
+	}
+	var data logspb.LogsData
+	if err = proto.Unmarshal(c.bodies[0], &data); err != nil {
+		t.Fatal(err)
+	}
+	scope := data.ResourceLogs[0].ScopeLogs[0]
+	if scope.Scope.Name != "app/db" || len(scope.LogRecords) != 3 {
+		t.Fatalf("unexpected scope: %v", scope)
+	}
+	record := scope.LogRecords[0]
+	if record.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_WARN || record.Body.GetStringValue() != "slow query" {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if len(record.Attributes) != 1 || record.Attributes[0].Key != "table" {
+		t.Errorf("unexpected attributes: %v", record.Attributes)
+	}
+	record = scope.LogRecords[2]
+	if record.Body.GetStringValue() != "invalid \uFFFD" || record.Attributes[0].Key != "bad\uFFFD" ||
+		record.Attributes[0].Value.GetStringValue() != "a\uFFFDb" {
+		t.Errorf("invalid UTF-8 was not replaced: %v", record)
+	}
+}
+
+func TestOTLPEngineJSON(t *testing.T) {
+	c := &collector{}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	engine, err := NewOTLPEngine(OTLPOptions{
+		Endpoint: server.URL,
+		Encoding: EncodingJSON,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := golog.New("app", engine)
+	log.Info().Param("count", 3).Send("quoted \"message\"\n\xff")
+	if err = log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.bodies) != 1 || c.types[0] != "application/json" {
+		t.Fatalf("expected 1 json request, got %d %v", len(c.bodies), c.types)
+	}
+	var body struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []struct {
+					SeverityNumber int `json:"severityNumber"`
+					Body           struct {
+						StringValue string `json:"stringValue"`
+					} `json:"body"`
+					Attributes []struct {
+						Key   string `json:"key"`
+						Value struct {
+							IntValue string `json:"intValue"`
+						} `json:"value"`
+					} `json:"attributes"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if encoded := appendJSONString(nil, "a\xffb"); string(encoded) != `"a\ufffdb"` {
+		t.Errorf("invalid UTF-8 was not escaped: %s", encoded)
+	}
+	if err = json.Unmarshal(c.bodies[0], &body); err != nil {
+		t.Fatalf("invalid json %s: %v", c.bodies[0], err)
+	}
+	record := body.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record.SeverityNumber != 9 || record.Body.StringValue != "quoted \"message\"\n\uFFFD" || record.Attributes[0].Value.IntValue != "3" {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
+
+func TestOTLPEngineRequeue(t *testing.T) {
+	c := &collector{failures: 2}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	engine, err := NewOTLPEngine(OTLPOptions{
+		Endpoint:     server.URL,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := golog.New("app", engine)
+	log.Info().Send("first")
+	if err = golog.Flush(context.Background()); err == nil {
+		t.Fatal("expected the export to fail")
+	}
+	log.Info().Send("second")
+	if err = log.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(c.bodies))
+	}
+	var data logspb.LogsData
+	if err = proto.Unmarshal(c.bodies[0], &data); err != nil {
+		t.Fatal(err)
+	}
+	records := data.ResourceLogs[0].ScopeLogs[0].LogRecords
+	if len(records) != 2 || records[0].Body.GetStringValue() != "first" || records[1].Body.GetStringValue() != "second" {
+		t.Errorf("failed records were not exported again: %v", records)
+	}
+}
+
+func TestOTLPEngineCloseDeadline(t *testing.T) {
+	c := &collector{failures: math.MaxInt}
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	engine, err := NewOTLPEngine(OTLPOptions{
+		Endpoint:     server.URL,
+		BatchSize:    1,
+		MaxRetries:   100,
+		RetryBackoff: time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := golog.New("app", engine)
+	log.Info().Send("first")
+	// let the background export start retrying
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err = log.Close(ctx); err == nil {
+		t.Error("expected the deadline to be exceeded")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("close took %s despite the deadline", elapsed)
+	}
+}
+
+func TestAnyValue(t *testing.T) {
+	if value := anyValue(uint(7)); value.GetIntValue() != 7 {
+		t.Errorf("unexpected uint value: %v", value)
+	}
+	cyclic := map[string]any{"a": 1}
+	cyclic["self"] = []any{cyclic}
+	encoded, err := proto.Marshal(anyValue(cyclic))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(encoded, []byte("!error: json: unsupported value: encountered a cycle")) {
+		t.Error("cycle not reported")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build race
+
+package golog
+
+func init() {
+	// sync.Pool drops items at random with the race detector, so allocations cannot be measured.
+	raceEnabled = true
+}
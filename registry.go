@@ -0,0 +1,48 @@
+package golog
+
+import "sync"
+
+// registry keeps references to loggers created with New, so their levels can be listed and changed at runtime.
+// References are weak when built with Go 1.24 or newer (see registry_weak.go).
+var registry = struct {
+	mut     sync.Mutex
+	loggers []loggerRef
+	prune   int
+}{
+	prune: 64,
+}
+
+func registerLogger(log *Logger) {
+	registry.mut.Lock()
+	defer registry.mut.Unlock()
+	registry.loggers = append(registry.loggers, newLoggerRef(log))
+	if len(registry.loggers) >= registry.prune {
+		registry.loggers = liveLoggers(registry.loggers)
+		registry.prune = max(64, len(registry.loggers)*2)
+	}
+}
+
+func liveLoggers(refs []loggerRef) []loggerRef {
+	live := refs[:0]
+	for _, ref := range refs {
+		if ref.Value() != nil {
+			live = append(live, ref)
+		}
+	}
+	clear(refs[len(live):])
+	return live
+}
+
+// Loggers returns loggers created with New that are still in use. Child loggers (Module, With, Copy)
+// are not listed; their levels follow Config module levels.
+func Loggers() []*Logger {
+	registry.mut.Lock()
+	defer registry.mut.Unlock()
+	loggers := make([]*Logger, 0, len(registry.loggers))
+	for _, ref := range registry.loggers {
+		if log := ref.Value(); log != nil {
+			loggers = append(loggers, log)
+		}
+	}
+	return loggers
+}
@@ -0,0 +1,16 @@
+//go:build !go1.24
+
+package golog
+
+// loggerRef keeps loggers alive before Go 1.24, which added weak pointers.
+type loggerRef struct {
+	log *Logger
+}
+
+func newLoggerRef(log *Logger) loggerRef {
+	return loggerRef{log: log}
+}
+
+func (r loggerRef) Value() *Logger {
+	return r.log
+}
@@ -0,0 +1,11 @@
+//go:build go1.24
+
+package golog
+
+import "weak"
+
+type loggerRef = weak.Pointer[Logger]
+
+func newLoggerRef(log *Logger) loggerRef {
+	return weak.Make(log)
+}
@@ -0,0 +1,366 @@
+package golog
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// RotationSchedule decides when RotatingFile rotates regardless of its size.
+type RotationSchedule uint8
+
+const (
+	RotateNever RotationSchedule = iota
+	RotateHourly
+	RotateDaily
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type RotatingFileOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated. Zero disables size based rotation.
+	MaxSize int64
+	// Schedule rotates the file every hour or day.
+	Schedule RotationSchedule
+	// MaxBackups is the number of rotated files to keep. Zero keeps all of them.
+	MaxBackups int
+	// MaxAge removes rotated files older than the given duration. Zero keeps all of them.
+	MaxAge time.Duration
+	// Compress compresses rotated files with gzip.
+	Compress bool
+	// Perm is used when creating files. Defaults to 0644.
+	Perm os.FileMode
+}
+
+// RotatingFile is an io.Writer for ColorEngine and JSONEngine that rotates the underlying file
+// by size or schedule and removes old backups. It is safe for concurrent use.
+//
+// Rotated files are named after the original file with the rotation time appended,
+// e.g. app.log becomes app-2006-01-02T15-04-05.000.log (or app-...log.gz when compressed). Files rotated within
+// the same millisecond get a counter suffix, e.g. app-2006-01-02T15-04-05.000-1.log.
+//
+// If rotation fails, the error is returned and the next Write reopens the file at the original path.
+type RotatingFile struct {
+	mut      sync.Mutex
+	path     string
+	opts     RotatingFileOptions
+	file     *os.File
+	closed   bool
+	size     int64
+	next     time.Time
+	millMut  sync.Mutex
+	millWait sync.WaitGroup
+}
+
+// currentTime is replaced in tests.
+var currentTime = time.Now
+
+// NewRotatingFile opens (or creates) the file at path for appending.
+func NewRotatingFile(path string, opts ...RotatingFileOptions) (*RotatingFile, error) {
+	f := &RotatingFile{path: path}
+	if len(opts) > 0 {
+		f.opts = opts[0]
+	}
+	if f.opts.Perm == 0 {
+		f.opts.Perm = 0644
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.opts.Perm)
+	if err != nil {
+		return fmt.Errorf("cannot open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("cannot stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	start := currentTime()
+	if f.size > 0 {
+		start = info.ModTime()
+	}
+	f.next = f.nextRotation(start)
+	return nil
+}
+
+func (f *RotatingFile) nextRotation(from time.Time) time.Time {
+	switch f.opts.Schedule {
+	case RotateHourly:
+		// Truncate works on absolute time, which is off for zones with offsets that are not whole hours
+		year, month, day := from.Date()
+		return time.Date(year, month, day, from.Hour()+1, 0, 0, 0, from.Location())
+	case RotateDaily:
+		year, month, day := from.Date()
+		return time.Date(year, month, day+1, 0, 0, 0, 0, from.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) shouldRotate(incoming int64) bool {
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+incoming > f.opts.MaxSize {
+		return true
+	}
+	return !f.next.IsZero() && !currentTime().Before(f.next)
+}
+
+// Rotate closes the current file, renames it to a backup and opens a new one.
+func (f *RotatingFile) Rotate() error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.rotate()
+}
+
+// rotate leaves f.file nil if the new file cannot be opened, so the next Write tries to open it again.
+func (f *RotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return errors.Join(fmt.Errorf("cannot close log file: %w", err), f.open())
+	}
+	now := currentTime()
+	backup := f.backupName(now)
+	if err = os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		// keep appending to the current file
+		return errors.Join(fmt.Errorf("cannot rename log file: %w", err), f.open())
+	}
+	if err = f.open(); err != nil {
+		return err
+	}
+	f.millWait.Add(1)
+	go f.mill(backup, now)
+	return nil
+}
+
+// Reopen closes and reopens the file at the same path. It should be used after the file was moved by an external tool.
+func (f *RotatingFile) Reopen() error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file != nil {
+		err := f.file.Close()
+		f.file = nil
+		if err != nil {
+			return errors.Join(fmt.Errorf("cannot close log file: %w", err), f.open())
+		}
+	}
+	return f.open()
+}
+
+// ReopenOnSignal calls Reopen every time the process receives one of given signals (SIGHUP by default).
+// Returned function stops listening.
+func (f *RotatingFile) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = f.Reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Close closes the file and waits for pending compression and cleanup of backups.
+func (f *RotatingFile) Close() error {
+	f.mut.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.closed = true
+	f.mut.Unlock()
+	f.millWait.Wait()
+	return err
+}
+
+// backupName returns a backup path for t that is not used yet, compressed or not.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	stamp := prefix + t.Format(backupTimeFormat)
+	name := filepath.Join(dir, stamp+ext)
+	for n := 1; fileExists(name) || fileExists(name+".gz"); n++ {
+		name = filepath.Join(dir, stamp+"-"+strconv.Itoa(n)+ext)
+	}
+	return name
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.path)
+	base := filepath.Base(f.path)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+	// n is the counter suffix of backups rotated within the same millisecond.
+	n int
+}
+
+// mill compresses the new backup and removes backups exceeding MaxBackups or MaxAge.
+func (f *RotatingFile) mill(backup string, now time.Time) {
+	defer f.millWait.Done()
+	f.millMut.Lock()
+	defer f.millMut.Unlock()
+	if f.opts.Compress {
+		_ = compressFile(backup, f.opts.Perm)
+	}
+	if f.opts.MaxBackups == 0 && f.opts.MaxAge == 0 {
+		return
+	}
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+	cutoff := now.Add(-f.opts.MaxAge)
+	for i, b := range backups {
+		if (f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups) || (f.opts.MaxAge > 0 && b.time.Before(cutoff)) {
+			_ = os.Remove(b.path)
+		}
+	}
+}
+
+// backups returns rotated files, newest first.
+func (f *RotatingFile) backups() ([]backupFile, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backupFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, prefix)
+		if trimmed, ok := strings.CutSuffix(stamp, ext+".gz"); ok {
+			stamp = trimmed
+		} else if trimmed, ok = strings.CutSuffix(stamp, ext); ok {
+			stamp = trimmed
+		} else {
+			continue
+		}
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		var n int
+		if suffix := stamp[len(backupTimeFormat):]; suffix != "" {
+			counter, ok := strings.CutPrefix(suffix, "-")
+			if n, err = strconv.Atoi(counter); !ok || err != nil || n < 1 {
+				continue
+			}
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t, n: n})
+	}
+	slices.SortFunc(backups, func(a, b backupFile) int {
+		if c := b.time.Compare(a.time); c != 0 {
+			return c
+		}
+		return b.n - a.n
+	})
+	return backups, nil
+}
+
+func compressFile(path string, perm os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
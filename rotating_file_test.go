@@ -0,0 +1,163 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	var mut sync.Mutex
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	currentTime = func() time.Time {
+		mut.Lock()
+		defer mut.Unlock()
+		now = now.Add(time.Second)
+		return now
+	}
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	file, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotatingFileOptions{
+		MaxSize:    100,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := New("app", JSONEngine(file))
+	for range 20 {
+		log.Info().Send("message")
+	}
+	if err = file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backups int
+	for _, entry := range entries {
+		if entry.Name() == "app.log" {
+			continue
+		}
+		if !strings.HasPrefix(entry.Name(), "app-") || !strings.HasSuffix(entry.Name(), ".log.gz") {
+			t.Errorf("unexpected file: %s", entry.Name())
+		}
+		backups++
+	}
+	if backups != 2 {
+		t.Errorf("expected 2 backups, got %d", backups)
+	}
+}
+
+func TestRotatingFileSchedule(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 1, 23, 59, 0, 0, time.Local)
+	currentTime = func() time.Time {
+		return now
+	}
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	file, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotatingFileOptions{Schedule: RotateDaily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	_, _ = file.Write([]byte("first\n"))
+	now = now.Add(2 * time.Minute)
+	_, _ = file.Write([]byte("second\n"))
+
+	content, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "second\n" {
+		t.Errorf("file was not rotated at midnight: %q", content)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "app-2024-05-02T00-01-00.000.log")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRotatingFileHourlyOffset(t *testing.T) {
+	zone := time.FixedZone("IST", 5*3600+30*60)
+	f := &RotatingFile{opts: RotatingFileOptions{Schedule: RotateHourly}}
+	next := f.nextRotation(time.Date(2024, 5, 1, 10, 45, 0, 0, zone))
+	if expected := time.Date(2024, 5, 1, 11, 0, 0, 0, zone); !next.Equal(expected) {
+		t.Errorf("expected %s, got %s", expected, next)
+	}
+}
+
+func TestRotatingFileSameTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	currentTime = func() time.Time {
+		return now
+	}
+	defer func() {
+		currentTime = time.Now
+	}()
+
+	file, err := NewRotatingFile(filepath.Join(dir, "app.log"), RotatingFileOptions{MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err = file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if err = file.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"app-2024-05-01T12-00-00.000-1.log": "second\n",
+		"app-2024-05-01T12-00-00.000-2.log": "third\n",
+	} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(content) != expected {
+			t.Errorf("unexpected content of %s: %q (%v)", name, content, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(dir, "app-2024-05-01T12-00-00.000.log")); !os.IsNotExist(err) {
+		t.Errorf("expected the oldest backup to be removed: %v", err)
+	}
+}
+
+func TestRotatingFileRecovers(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	file, err := NewRotatingFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err = os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = file.Rotate(); err == nil {
+		t.Fatal("expected rotation to fail")
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = file.Write([]byte("message\n")); err != nil {
+		t.Fatalf("file was not reopened: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "app.log")); err != nil || string(content) != "message\n" {
+		t.Errorf("unexpected content: %q (%v)", content, err)
+	}
+}
@@ -1,7 +1,10 @@
 package sentry
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"slices"
 	"time"
 
@@ -9,26 +12,60 @@ import (
 	"github.com/getsentry/sentry-go"
 )
 
+// stacktrace converts the stack captured by golog. Sentry expects the oldest frame first.
+func stacktrace(stack golog.StackTrace) *sentry.Stacktrace {
+	frames := stack.Frames()
+	st := &sentry.Stacktrace{Frames: make([]sentry.Frame, 0, len(frames))}
+	for i := len(frames) - 1; i >= 0; i-- {
+		st.Frames = append(st.Frames, sentry.NewFrame(runtime.Frame{
+			Function: frames[i].Function,
+			File:     frames[i].File,
+			Line:     frames[i].Line,
+		}))
+	}
+	return st
+}
+
+// setErrorAndStack records the error chain as exceptions, with the captured stack (if any) attached to the most
+// recent one, unless the error carries its own stack trace. Messages without an error get the stack as the current thread.
+func setErrorAndStack(ev *sentry.Event, data *golog.MessageData) {
+	if data.Error != nil {
+		ev.SetException(data.Error, -1)
+		if data.StackIncluded && sentry.ExtractStacktrace(data.Error) == nil {
+			ev.Exception[len(ev.Exception)-1].Stacktrace = stacktrace(data.Stack)
+		}
+		return
+	}
+	if data.StackIncluded {
+		ev.Threads = []sentry.Thread{{
+			Stacktrace: stacktrace(data.Stack),
+			Crashed:    data.Level.Base() == golog.LevelPanic,
+			Current:    true,
+		}}
+	}
+}
+
+// sentryLevel maps the level by its base, so custom levels registered in golog are supported.
 func sentryLevel(level golog.Level) sentry.Level {
-	switch level {
-	case golog.LevelTrace:
-		return sentry.LevelDebug
-	case golog.LevelDebug:
+	switch level.Base() {
+	case golog.LevelTrace, golog.LevelDebug:
 		return sentry.LevelDebug
-	case golog.LevelInfo:
-		return sentry.LevelInfo
 	case golog.LevelWarning:
 		return sentry.LevelWarning
 	case golog.LevelError:
 		return sentry.LevelError
 	case golog.LevelPanic:
 		return sentry.LevelFatal
+	default:
+		return sentry.LevelInfo
 	}
-	panic("invalid logging level")
 }
 
 const identifier = "boomfinity.golog"
 
+// flushTimeout is used when the context passed to Flush has no deadline.
+const flushTimeout = 2 * time.Second
+
 func New(opts sentry.ClientOptions, levels ...golog.Level) (golog.WriteEngine, error) {
 	c, err := sentry.NewClient(opts)
 	if err != nil {
@@ -39,17 +76,19 @@ func New(opts sentry.ClientOptions, levels ...golog.Level) (golog.WriteEngine, e
 	return NewWithHub(hub, levels...)
 }
 
+// NewWithHub creates an engine reporting messages with given levels (panic and error by default) to hub.
+// Events are delivered in the background; call golog.Shutdown before the application exits to flush them.
+// If the message carries a context with a sentry hub (see golog.Message.Ctx), the event is captured by that hub.
 func NewWithHub(hub *sentry.Hub, levels ...golog.Level) (golog.WriteEngine, error) {
 	if len(levels) == 0 {
 		levels = append(levels, golog.LevelPanic, golog.LevelError)
 	}
-	return func(log *golog.Logger, data *golog.MessageData) {
+	return golog.Managed(func(log *golog.Logger, data *golog.MessageData) {
 		if !slices.Contains(levels, data.Level) {
-			println("skip")
 			return
 		}
 		ev := sentry.NewEvent()
-		ev.Timestamp = time.Now()
+		ev.Timestamp = data.Time
 		ev.Logger = "golog"
 		ev.Level = sentryLevel(data.Level)
 		if len(data.Message) > 0 {
@@ -61,8 +100,11 @@ func NewWithHub(hub *sentry.Hub, levels ...golog.Level) (golog.WriteEngine, erro
 		if len(data.Params) > 0 {
 			ctx["message_params"] = data.Params
 		}
-		if len(log.Params()) > 0 {
-			ctx["params"] = log.Params()
+		if params := data.LoggerParams(); len(params) > 0 {
+			ctx["params"] = params
+		}
+		if params := data.ContextParams(); len(params) > 0 {
+			ctx["context_params"] = params
 		}
 		if data.ExitCode != 0 {
 			ctx["exit_code"] = data.ExitCode
@@ -73,11 +115,30 @@ func NewWithHub(hub *sentry.Hub, levels ...golog.Level) (golog.WriteEngine, erro
 		if data.Details != nil {
 			ctx["details"] = data.Details
 		}
-		if data.StackIncluded {
-			ev.SetException(data.Error, -1)
+		setErrorAndStack(ev, data)
+		if data.Caller.Defined() {
+			// the transaction names the operation (e.g. an HTTP route), so the caller goes to tags
+			ev.Tags["caller"] = string(data.Caller.AppendShort(nil))
+			ev.Tags["caller_function"] = data.Caller.Function
 		}
 		ev.Contexts["golog"] = ctx
-		hub.CaptureEvent(ev)
-		hub.Flush(2 * time.Second)
-	}, nil
+		target := hub
+		if data.Context != nil {
+			if ctxHub := sentry.GetHubFromContext(data.Context); ctxHub != nil {
+				target = ctxHub
+			}
+		}
+		target.CaptureEvent(ev)
+	}, golog.EngineHooks{
+		Flush: func(ctx context.Context) error {
+			timeout := flushTimeout
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = time.Until(deadline)
+			}
+			if !hub.Flush(timeout) {
+				return errors.New("sentry: flush timed out")
+			}
+			return nil
+		},
+	}), nil
 }
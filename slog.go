@@ -0,0 +1,125 @@
+package golog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler that sends every record through a golog Logger,
+// so libraries using log/slog end up in the same engines as the rest of the application. Contexts passed to
+// the *Context methods of slog.Logger replace the context of the logger; context.Background does not.
+type SlogHandler struct {
+	log *Logger
+}
+
+var _ slog.Handler = (*SlogHandler)(nil)
+
+// NewSlogHandler creates a slog.Handler that writes records to log.
+func NewSlogHandler(log *Logger) *SlogHandler {
+	return &SlogHandler{log: log}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.log.Enabled(levelFromSlog(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	msg := newMessage(h.log, levelFromSlog(record.Level))
+	if !msg.Enabled() {
+		return nil
+	}
+	msg.data.Time = record.Time
+	// slog.Logger.Info and others pass context.Background, which must not replace the context of
+	// a logger from FromContext
+	if ctx != nil && ctx != context.Background() && ctx != context.TODO() {
+		msg.data.Context = ctx
+	}
+	if record.PC != 0 && h.log.IncludeCaller() {
+		msg.data.Caller = callerFromPCs([]uintptr{record.PC})
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		msg.data.Params = appendSlogAttr(msg.data.Params, "", attr)
+		return true
+	})
+	msg.sendText(record.Message)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	var params []Parameter
+	for _, attr := range attrs {
+		params = appendSlogAttr(params, "", attr)
+	}
+	return &SlogHandler{log: h.log.With(params...)}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{log: h.log.Module(name)}
+}
+
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarning
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	case level >= slog.LevelDebug:
+		return LevelDebug
+	default:
+		return LevelTrace
+	}
+}
+
+// appendSlogAttr flattens attr into params. Attributes nested in groups get dotted names.
+func appendSlogAttr(params []Parameter, prefix string, attr slog.Attr) []Parameter {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return params
+	}
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, child := range attr.Value.Group() {
+			params = appendSlogAttr(params, prefix, child)
+		}
+		return params
+	}
+	return append(params, slogParam(prefix+attr.Key, attr.Value))
+}
+
+func slogParam(name string, value slog.Value) Parameter {
+	switch value.Kind() {
+	case slog.KindString:
+		return Str(name, value.String())
+	case slog.KindInt64:
+		return Int64(name, value.Int64())
+	case slog.KindUint64:
+		return Uint64(name, value.Uint64())
+	case slog.KindFloat64:
+		return Float(name, value.Float64())
+	case slog.KindBool:
+		return Bool(name, value.Bool())
+	case slog.KindDuration:
+		return Dur(name, value.Duration())
+	case slog.KindTime:
+		return Time(name, value.Time())
+	case slog.KindGroup:
+		return Parameter{Name: name, Value: slogValueAny(value)}
+	default:
+		return Any(name, value.Any())
+	}
+}
+
+// Slog returns a *slog.Logger that writes through l.
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
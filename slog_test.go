@@ -0,0 +1,132 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+type jsonLine struct {
+	Level   string      `json:"level"`
+	Module  []string    `json:"module"`
+	Context []Parameter `json:"context"`
+	Params  []Parameter `json:"params"`
+	Message string      `json:"message"`
+}
+
+func decodeLines(t *testing.T, buff *bytes.Buffer) []jsonLine {
+	t.Helper()
+	var lines []jsonLine
+	dec := json.NewDecoder(buff)
+	for dec.More() {
+		var line jsonLine
+		if err := dec.Decode(&line); err != nil {
+			t.Fatalf("cannot decode output: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestSlogHandler(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff))
+	logger := slog.New(NewSlogHandler(log))
+
+	logger.Debug("hidden")
+	logger.With("request", 5).WithGroup("db").Warn("slow query", "took", 3, slog.Group("conn", "host", "localhost"))
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	line := lines[0]
+	if line.Level != "WARNING" || line.Message != "slow query" {
+		t.Errorf("unexpected line: %+v", line)
+	}
+	if len(line.Module) != 2 || line.Module[1] != "db" {
+		t.Errorf("unexpected modules: %v", line.Module)
+	}
+	if len(line.Context) != 1 || line.Context[0].Name != "request" {
+		t.Errorf("unexpected context: %v", line.Context)
+	}
+	if len(line.Params) != 2 || line.Params[0].Name != "took" || line.Params[1].Name != "conn.host" {
+		t.Errorf("unexpected params: %v", line.Params)
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	h := NewSlogHandler(New("app", JSONEngine(new(bytes.Buffer))).SetLevel(LevelWarning))
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info should be disabled")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError+4) {
+		t.Error("error should be enabled")
+	}
+}
+
+func TestLoggerSlog(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff)).Module("db").SetLevel(LevelInfo)
+	logger := log.Slog()
+
+	logger.Debug("hidden")
+	logger.Error("query failed", "table", "users", slog.Int("rows", 3))
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	line := lines[0]
+	if line.Level != "ERROR" || line.Message != "query failed" {
+		t.Errorf("unexpected line: %+v", line)
+	}
+	if len(line.Module) != 2 || line.Module[0] != "app" || line.Module[1] != "db" {
+		t.Errorf("unexpected modules: %v", line.Module)
+	}
+	if len(line.Params) != 2 || line.Params[0].Name != "table" || line.Params[0].Value != "users" ||
+		line.Params[1].Name != "rows" || line.Params[1].Value != float64(3) {
+		t.Errorf("unexpected params: %v", line.Params)
+	}
+}
+
+func TestLoggerSlogContext(t *testing.T) {
+	buff := new(bytes.Buffer)
+	ctx := WithLogger(WithParams(context.Background(), Str("request", "abc")), New("app", JSONEngine(buff)))
+	logger := FromContext(ctx).Slog()
+
+	logger.Info("without context")
+	logger.InfoContext(WithParams(context.Background(), Str("user", "joe")), "with context")
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if len(lines[0].Context) != 1 || lines[0].Context[0].Name != "request" || lines[0].Context[0].Value != "abc" {
+		t.Errorf("logger context lost: %v", lines[0].Context)
+	}
+	if len(lines[1].Context) != 1 || lines[1].Context[0].Name != "user" {
+		t.Errorf("message context not used: %v", lines[1].Context)
+	}
+}
+
+func TestStdLog(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff)).Module("http")
+	log.Param("server", "api")
+	log.StdLog(LevelError).Printf("http: TLS handshake error from %s", "127.0.0.1")
+
+	lines := decodeLines(t, buff)
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d", len(lines))
+	}
+	line := lines[0]
+	if line.Level != "ERROR" || line.Message != "http: TLS handshake error from 127.0.0.1" {
+		t.Errorf("unexpected line: %+v", line)
+	}
+	if len(line.Module) != 2 || len(line.Context) != 1 {
+		t.Errorf("modules or context lost: %+v", line)
+	}
+}
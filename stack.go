@@ -0,0 +1,127 @@
+package golog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame is a single resolved frame of a stack trace.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Defined reports whether the frame has a location.
+func (f Frame) Defined() bool {
+	return f.File != ""
+}
+
+// AppendShort appends the file with its parent directory and the line, e.g. "golog/message.go:42".
+func (f Frame) AppendShort(dst []byte) []byte {
+	file := f.File
+	if idx := strings.LastIndexByte(file, '/'); idx != -1 {
+		if idx = strings.LastIndexByte(file[:idx], '/'); idx != -1 {
+			file = file[idx+1:]
+		}
+	}
+	dst = append(dst, file...)
+	dst = append(dst, ':')
+	return strconv.AppendInt(dst, int64(f.Line), 10)
+}
+
+// StackTrace holds program counters of a captured stack. They are resolved into frames only when needed.
+type StackTrace struct {
+	pcs []uintptr
+}
+
+// NewStackTrace creates a stack trace from program counters returned by runtime.Callers.
+func NewStackTrace(pcs []uintptr) StackTrace {
+	return StackTrace{pcs: pcs}
+}
+
+// PCs returns program counters of the stack trace.
+func (s StackTrace) PCs() []uintptr {
+	return s.pcs
+}
+
+func (s StackTrace) Len() int {
+	return len(s.pcs)
+}
+
+// capture records the stack of the caller, skipping skip frames above it.
+// The buffer grows as needed, so the trace is never truncated.
+func (s *StackTrace) capture(skip int) {
+	pcs := s.pcs[:cap(s.pcs)]
+	if len(pcs) == 0 {
+		pcs = make([]uintptr, 32)
+	}
+	for {
+		n := runtime.Callers(skip+2, pcs)
+		if n < len(pcs) {
+			s.pcs = pcs[:n]
+			return
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+}
+
+// AppendFrames resolves the stack trace and appends its frames to dst, the innermost first.
+// Leading frames of the runtime package (e.g. panic handling) are skipped.
+func (s StackTrace) AppendFrames(dst []Frame) []Frame {
+	if len(s.pcs) == 0 {
+		return dst
+	}
+	frames := runtime.CallersFrames(s.pcs)
+	leading := true
+	for {
+		frame, more := frames.Next()
+		if leading && strings.HasPrefix(frame.Function, "runtime.") && more {
+			continue
+		}
+		leading = false
+		dst = append(dst, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			return dst
+		}
+	}
+}
+
+// Frames resolves the stack trace into frames, the innermost first.
+func (s StackTrace) Frames() []Frame {
+	return s.AppendFrames(make([]Frame, 0, len(s.pcs)))
+}
+
+// AppendText appends the stack trace in a format similar to panics: function on one line, location on the next.
+func (s StackTrace) AppendText(dst []byte) []byte {
+	if len(s.pcs) == 0 {
+		return dst
+	}
+	frames := runtime.CallersFrames(s.pcs)
+	leading := true
+	for {
+		frame, more := frames.Next()
+		if leading && strings.HasPrefix(frame.Function, "runtime.") && more {
+			continue
+		}
+		leading = false
+		dst = append(dst, frame.Function...)
+		dst = append(dst, "\n\t"...)
+		dst = append(dst, frame.File...)
+		dst = append(dst, ':')
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, '\n')
+		if !more {
+			return dst
+		}
+	}
+}
+
+func (s StackTrace) String() string {
+	return string(s.AppendText(nil))
+}
@@ -0,0 +1,96 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func stackEngine(stacks *[][]Frame) WriteEngine {
+	return func(log *Logger, data *MessageData) {
+		if data.StackIncluded {
+			*stacks = append(*stacks, data.Stack.Frames())
+		}
+	}
+}
+
+func recurse(depth int, fn func()) {
+	if depth == 0 {
+		fn()
+		return
+	}
+	recurse(depth-1, fn)
+}
+
+func TestStackTrace(t *testing.T) {
+	var stacks [][]Frame
+	log := New("app", stackEngine(&stacks))
+
+	var lines []int
+	lines = append(lines, currentLine()+1)
+	log.Info().Stack().Send("stack")
+	lines = append(lines, currentLine()+1)
+	log.Error().Throw(errors.New("throw"))
+	lines = append(lines, currentLine()+1)
+	log.Fatal(0).Send("panic level")
+	lines = append(lines, panicking(log))
+
+	if len(stacks) != len(lines) {
+		t.Fatalf("expected %d stacks, got %d", len(lines), len(stacks))
+	}
+	for i, frames := range stacks {
+		if len(frames) == 0 {
+			t.Fatalf("stack %d is empty", i)
+		}
+		if !strings.HasSuffix(frames[0].File, "stack_test.go") && !strings.HasSuffix(frames[0].File, "caller_test.go") || frames[0].Line != lines[i] {
+			t.Errorf("stack %d: expected to start at line %d, got %s:%d (%s)", i, lines[i], frames[0].File, frames[0].Line, frames[0].Function)
+		}
+	}
+}
+
+func TestStackTraceDepth(t *testing.T) {
+	var stacks [][]Frame
+	log := New("app", stackEngine(&stacks))
+	recurse(Config.StackTraceFrames()*2, func() {
+		log.Info().Stack().Send("deep")
+	})
+	if len(stacks) != 1 {
+		t.Fatalf("expected 1 stack, got %d", len(stacks))
+	}
+	count := 0
+	for _, frame := range stacks[0] {
+		if strings.HasSuffix(frame.Function, ".recurse") {
+			count++
+		}
+	}
+	if count != Config.StackTraceFrames()*2+1 {
+		t.Errorf("expected %d recurse frames, got %d", Config.StackTraceFrames()*2+1, count)
+	}
+}
+
+func TestStackTraceJSON(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff))
+	line := currentLine() + 1
+	log.Info().Stack().Send("stack")
+
+	var out struct {
+		Stack []Frame `json:"stack"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &out); err != nil {
+		t.Fatalf("cannot decode output: %v", err)
+	}
+	if len(out.Stack) == 0 || out.Stack[0].Line != line || !strings.HasSuffix(out.Stack[0].Function, ".TestStackTraceJSON") {
+		t.Errorf("unexpected stack: %+v", out.Stack)
+	}
+}
+
+func TestStackTraceBufferSize(t *testing.T) {
+	defer Config.SetStackTraceFrames(Config.StackTraceFrames())
+	Config.SetStackTraceBufferSize(1024)
+	if Config.StackTraceFrames() != 128 || Config.StackTraceBufferSize() != 1024 {
+		t.Errorf("unexpected size: %d frames, %d bytes", Config.StackTraceFrames(), Config.StackTraceBufferSize())
+	}
+}
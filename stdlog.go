@@ -0,0 +1,27 @@
+package golog
+
+import (
+	"bytes"
+	"log"
+)
+
+type stdLogWriter struct {
+	log   *Logger
+	level Level
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if !w.log.Enabled(w.level) {
+		return n, nil
+	}
+	// skip Write, log.Logger.output and log.Logger.Print* methods
+	newMessage(w.log, w.level).CallerSkip(3).sendText(string(bytes.TrimSuffix(p, []byte{'\n'})))
+	return n, nil
+}
+
+// StdLog returns a *log.Logger from the standard library. Every line written to it
+// is sent as a message with the given level, keeping modules and parameters of l.
+func (l *Logger) StdLog(level Level) *log.Logger {
+	return log.New(stdLogWriter{log: l, level: level}, "", 0)
+}
@@ -0,0 +1,43 @@
+package golog
+
+import (
+	"context"
+	"encoding/hex"
+)
+
+// TraceInfo identifies the trace and span a message was sent in.
+type TraceInfo struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// TraceExtractor reads trace information from a context. The otel package provides one for OpenTelemetry.
+type TraceExtractor func(ctx context.Context) (TraceInfo, bool)
+
+// AppendTraceID appends the trace id as 32 hex characters.
+func (t TraceInfo) AppendTraceID(dst []byte) []byte {
+	return hex.AppendEncode(dst, t.TraceID[:])
+}
+
+// AppendSpanID appends the span id as 16 hex characters.
+func (t TraceInfo) AppendSpanID(dst []byte) []byte {
+	return hex.AppendEncode(dst, t.SpanID[:])
+}
+
+// AppendFlags appends trace flags as 2 hex characters.
+func (t TraceInfo) AppendFlags(dst []byte) []byte {
+	return hex.AppendEncode(dst, []byte{t.Flags})
+}
+
+// Trace returns trace information of the message context, using the extractor set with Config.SetTraceExtractor.
+func (d *MessageData) Trace() (TraceInfo, bool) {
+	if d.Context == nil {
+		return TraceInfo{}, false
+	}
+	extract := Config.TraceExtractor()
+	if extract == nil {
+		return TraceInfo{}, false
+	}
+	return extract(d.Context)
+}
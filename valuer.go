@@ -0,0 +1,61 @@
+package golog
+
+import (
+	"log/slog"
+)
+
+type lazyValue func() any
+
+func (fn lazyValue) LogValue() slog.Value {
+	return slog.AnyValue(fn())
+}
+
+// Lazy calls fn only when a message with the value is written.
+func Lazy(fn func() any) slog.LogValuer {
+	return lazyValue(fn)
+}
+
+// resolveValues converts params of the message into typed ones, replacing slog.LogValuer params and details with
+// their values. Params of log and the message context are resolved into copies, as they are shared by messages.
+// It runs once, after the level check and before engines.
+func resolveValues(log *Logger, data *MessageData) {
+	for i := range data.Params {
+		data.Params[i] = data.Params[i].resolve()
+	}
+	loggerParams, contextParams := log.Params(), ContextParams(data.Context)
+	if needsResolving(loggerParams) || needsResolving(contextParams) {
+		params := append(append(data.resolvedParams[:0], loggerParams...), contextParams...)
+		for i := range params {
+			params[i] = params[i].resolve()
+		}
+		data.resolvedParams = params
+		n := len(loggerParams)
+		loggerParams, contextParams = params[:n:n], params[n:]
+	}
+	data.loggerParams, data.contextParams = loggerParams, contextParams
+	if valuer, ok := data.Details.(slog.LogValuer); ok {
+		data.Details = slogValueAny(valuer.LogValue().Resolve())
+	}
+}
+
+// slogValueAny returns the value boxed, with groups converted to maps.
+func slogValueAny(value slog.Value) any {
+	if value.Kind() != slog.KindGroup {
+		return value.Any()
+	}
+	attrs := value.Group()
+	group := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		group[attr.Key] = slogValueAny(attr.Value.Resolve())
+	}
+	return group
+}
+
+func needsResolving(params []Parameter) bool {
+	for i := range params {
+		if params[i].kind == KindAny && params[i].Value != nil {
+			return true
+		}
+	}
+	return false
+}
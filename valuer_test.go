@@ -0,0 +1,85 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+type user struct {
+	id       int
+	password string
+}
+
+func (u user) LogValue() slog.Value {
+	return slog.IntValue(u.id)
+}
+
+func TestLogValuer(t *testing.T) {
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff)).Param("owner", user{id: 1, password: "secret"})
+	log.SetLevel(LevelInfo)
+
+	calls := 0
+	expensive := Lazy(func() any {
+		calls++
+		return "computed"
+	})
+	log.Debug().Param("lazy", expensive).Send("disabled")
+	if calls != 0 || buff.Len() != 0 {
+		t.Fatalf("disabled message resolved its values")
+	}
+
+	log.Info().
+		Param("lazy", expensive).
+		Param("user", user{id: 2, password: "secret"}).
+		Param("group", Lazy(func() any { return slog.GroupValue(slog.String("a", "b")) })).
+		Details(user{id: 3}).
+		Send("enabled")
+	if calls != 1 {
+		t.Errorf("expected the lazy value to be resolved once, got %d calls", calls)
+	}
+	if bytes.Contains(buff.Bytes(), []byte("secret")) {
+		t.Errorf("password was logged: %s", buff.String())
+	}
+
+	var out struct {
+		Context []Parameter `json:"context"`
+		Params  []Parameter `json:"params"`
+		Details any         `json:"details"`
+	}
+	if err := json.Unmarshal(buff.Bytes(), &out); err != nil {
+		t.Fatalf("cannot decode output: %v", err)
+	}
+	if len(out.Context) != 1 || out.Context[0].Value != float64(1) {
+		t.Errorf("unexpected context: %+v", out.Context)
+	}
+	if len(out.Params) != 3 || out.Params[0].Value != "computed" || out.Params[1].Value != float64(2) {
+		t.Errorf("unexpected params: %+v", out.Params)
+	}
+	if group, ok := out.Params[2].Value.(map[string]any); !ok || group["a"] != "b" {
+		t.Errorf("unexpected group: %+v", out.Params[2].Value)
+	}
+	if out.Details != float64(3) {
+		t.Errorf("unexpected details: %v", out.Details)
+	}
+}
+
+func TestLoggerValuerResolvedOnce(t *testing.T) {
+	calls := 0
+	expensive := Lazy(func() any {
+		calls++
+		return "computed"
+	})
+	buff := new(bytes.Buffer)
+	log := New("app", JSONEngine(buff), LogfmtEngine(buff), TextEngine(nil, buff)).Param("lazy", expensive)
+	log.Info().Ctx(WithParams(context.Background(), Parameter{Name: "ctx", Value: expensive})).Send("message")
+	if calls != 2 {
+		t.Errorf("expected logger and context values to be resolved once each, got %d calls", calls)
+	}
+	if bytes.Count(buff.Bytes(), []byte("computed")) != 6 {
+		t.Errorf("unexpected output: %s", buff.String())
+	}
+}